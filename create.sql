@@ -1,15 +1,107 @@
 CREATE TABLE ui_translations
 (
     id          SERIAL PRIMARY KEY,
+    tenant_id   UUID,
     user_id     UUID,
+    scope       TEXT NOT NULL DEFAULT '',
+    namespace   TEXT NOT NULL DEFAULT '',
     key_path    TEXT NOT NULL,
     lang        TEXT NOT NULL,
-    value       TEXT NOT NULL,
-    tooltip     TEXT NULL,
+    variant     TEXT NOT NULL DEFAULT '', -- experiment variant; '' = control
+    value       TEXT NOT NULL, -- latest value, possibly awaiting review
+    approved_value TEXT, -- value served to lookups; NULL until first approved
+    status      TEXT NOT NULL DEFAULT 'approved'
+        CHECK (status IN ('draft', 'in_review', 'approved', 'rejected')),
+    submitted_by UUID,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_comment TEXT,
+    attributes  JSONB NOT NULL DEFAULT '{}', -- tooltip, placeholder, aria_label, ...
+    pending_attributes JSONB, -- attributes of a value awaiting review
+    source_hash TEXT, -- sha256 of the source-language value this row was translated from
+    effective_from  TIMESTAMP WITH TIME ZONE, -- NULL = always in effect
+    effective_until TIMESTAMP WITH TIME ZONE, -- NULL = no end
+    pending_effective_until TIMESTAMP WITH TIME ZONE, -- end of a value awaiting review
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_by  UUID,
-    unique (user_id, key_path, lang)
+    -- NULLS NOT DISTINCT (PostgreSQL 15+) makes global rows, whose tenant_id
+    -- and user_id are NULL, unique too
+    CONSTRAINT ui_translations_key_unique
+        UNIQUE NULLS NOT DISTINCT (tenant_id, user_id, scope, namespace, key_path, lang, variant, effective_from),
+    CONSTRAINT ui_translations_effective_window
+        CHECK (effective_until IS NULL OR effective_from IS NULL OR effective_until > effective_from),
+    CONSTRAINT ui_translations_pending_effective_window
+        CHECK (pending_effective_until IS NULL OR effective_from IS NULL OR pending_effective_until > effective_from)
 );
 
 ALTER TABLE ui_translations
     OWNER TO postgres;
+
+CREATE TABLE ui_translation_audit
+(
+    id               BIGSERIAL PRIMARY KEY,
+    action           TEXT NOT NULL,
+    source_tenant_id UUID,
+    source_user_id   UUID,
+    source_scope     TEXT,
+    tenant_id        UUID,
+    user_id          UUID,
+    scope            TEXT NOT NULL DEFAULT '',
+    namespace        TEXT NOT NULL DEFAULT '',
+    key_path         TEXT NOT NULL,
+    lang             TEXT NOT NULL,
+    old_value        TEXT,
+    new_value        TEXT,
+    actor            UUID,
+    created_at       TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE ui_translation_audit
+    OWNER TO postgres;
+
+CREATE TABLE translation_keys
+(
+    namespace   TEXT NOT NULL DEFAULT '',
+    key_path    TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    notes       TEXT NOT NULL DEFAULT '',
+    screenshot  TEXT NOT NULL DEFAULT '',
+    max_length  INTEGER CHECK (max_length > 0),
+    tags        TEXT[] NOT NULL DEFAULT '{}',
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (namespace, key_path)
+);
+
+ALTER TABLE translation_keys
+    OWNER TO postgres;
+
+CREATE TABLE ui_missing_keys
+(
+    tenant_id  UUID,
+    scope      TEXT NOT NULL DEFAULT '',
+    namespace  TEXT NOT NULL DEFAULT '',
+    key_path   TEXT NOT NULL,
+    lang       TEXT NOT NULL,
+    count      BIGINT NOT NULL DEFAULT 0,
+    first_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_seen  TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT ui_missing_keys_unique
+        UNIQUE NULLS NOT DISTINCT (tenant_id, scope, namespace, key_path, lang)
+);
+
+ALTER TABLE ui_missing_keys
+    OWNER TO postgres;
+
+CREATE TABLE ui_key_usage
+(
+    namespace     TEXT NOT NULL DEFAULT '',
+    key_path      TEXT NOT NULL,
+    count         BIGINT NOT NULL DEFAULT 0,
+    last_accessed TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (namespace, key_path)
+);
+
+ALTER TABLE ui_key_usage
+    OWNER TO postgres;
@@ -2,8 +2,11 @@ package example
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/jackc/pgx/v5"
 	"go-i18n-db/i18n"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -40,3 +43,84 @@ func LoadAndSaveAutoLang(conn *pgx.Conn, filePath string, userID *string) error
 
 	return LoadAndSave(conn, filePath, lang, userID)
 }
+
+// ImportAll loads several JSON files, guessing each language from its file
+// name, and saves them in one transaction so a failure leaves no partial import.
+func ImportAll(conn *pgx.Conn, filePaths []string, userID *string) error {
+	imp := i18n.NewImport()
+	for _, filePath := range filePaths {
+		base := filepath.Base(filePath)
+		lang := strings.TrimSuffix(base, filepath.Ext(base))
+		if err := imp.AddFile(filePath, lang, userID); err != nil {
+			return err
+		}
+	}
+	return imp.Commit(context.Background(), conn)
+}
+
+// LoadDirAndSave loads every catalog under dir whose path matches one of the
+// patterns (i18n.LayoutLangDir by default) and saves them in one transaction.
+func LoadDirAndSave(conn *pgx.Conn, dir string, userID *string, patterns ...string) error {
+	translations, err := i18n.LoadDir(os.DirFS(dir), patterns...)
+	if err != nil {
+		return err
+	}
+	for i := range translations {
+		translations[i].UserID = userID
+	}
+
+	imp := i18n.NewImport()
+	imp.Add(translations...)
+	return imp.Commit(context.Background(), conn)
+}
+
+// ReportCustomizations writes what a tenant has customized for lang, as a
+// table or as JSON that can be reviewed or migrated to another environment.
+func ReportCustomizations(conn *pgx.Conn, w io.Writer, lang, tenantID string, asJSON bool) error {
+	report, err := i18n.DiffOverrides(context.Background(), conn, lang, nil, i18n.WithTenant(tenantID))
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.WriteText(w)
+}
+
+// ValidateDirBeforeImport checks every catalog under dir against sourceLang
+// and imports them only if no issue is found; otherwise the report is
+// written to w.
+func ValidateDirBeforeImport(conn *pgx.Conn, w io.Writer, dir, sourceLang string, patterns ...string) error {
+	translations, err := i18n.LoadDir(os.DirFS(dir), patterns...)
+	if err != nil {
+		return err
+	}
+	report := i18n.ValidateMessages(translations, sourceLang)
+	if len(report.Issues) > 0 {
+		if err = report.WriteText(w); err != nil {
+			return err
+		}
+		return report.Err()
+	}
+
+	imp := i18n.NewImport()
+	imp.Add(translations...)
+	return imp.Commit(context.Background(), conn)
+}
+
+// ReportCoverage writes how complete each language is compared with
+// sourceLang, as a table or as JSON.
+func ReportCoverage(conn *pgx.Conn, w io.Writer, sourceLang string, asJSON bool) error {
+	report, err := i18n.Coverage(context.Background(), conn, sourceLang)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.WriteText(w)
+}
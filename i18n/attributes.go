@@ -0,0 +1,55 @@
+package i18n
+
+// Well-known attribute names. Any other name can be stored as well: attributes
+// are kept in the JSONB attributes column and need no schema change.
+const (
+	AttrTooltip     = "tooltip"
+	AttrPlaceholder = "placeholder"
+	AttrAriaLabel   = "aria_label"
+	AttrShort       = "short"
+	AttrLong        = "long"
+)
+
+// Attributes holds the per-string variants stored next to a translation's
+// value, keyed by attribute name.
+type Attributes map[string]string
+
+// Get returns the named attribute, or "" if it is not set.
+func (a Attributes) Get(name string) string {
+	return a[name]
+}
+
+// Has reports whether the named attribute is set.
+func (a Attributes) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// Tooltip returns the AttrTooltip attribute.
+func (a Attributes) Tooltip() string { return a[AttrTooltip] }
+
+// Placeholder returns the AttrPlaceholder attribute.
+func (a Attributes) Placeholder() string { return a[AttrPlaceholder] }
+
+// AriaLabel returns the AttrAriaLabel attribute.
+func (a Attributes) AriaLabel() string { return a[AttrAriaLabel] }
+
+// Short returns the AttrShort attribute.
+func (a Attributes) Short() string { return a[AttrShort] }
+
+// Long returns the AttrLong attribute.
+func (a Attributes) Long() string { return a[AttrLong] }
+
+// attributes returns the attributes to store for t: its Attributes plus the
+// deprecated ToolTip field unless Attributes already sets a tooltip. The
+// result is never nil, so it encodes as a JSON object.
+func (t Translation) attributes() Attributes {
+	attrs := make(Attributes, len(t.Attributes)+1)
+	for name, value := range t.Attributes {
+		attrs[name] = value
+	}
+	if t.ToolTip != "" && !attrs.Has(AttrTooltip) {
+		attrs[AttrTooltip] = t.ToolTip
+	}
+	return attrs
+}
@@ -0,0 +1,33 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAttributes_Accessors(t *testing.T) {
+	attrs := Attributes{AttrTooltip: "Your profile", AttrAriaLabel: "Open profile", "badge": "New"}
+	assert.Equal(t, "Your profile", attrs.Tooltip())
+	assert.Equal(t, "Open profile", attrs.AriaLabel())
+	assert.Equal(t, "", attrs.Placeholder())
+	assert.Equal(t, "New", attrs.Get("badge"))
+	assert.True(t, attrs.Has("badge"))
+	assert.False(t, attrs.Has(AttrShort))
+
+	var none Attributes
+	assert.Equal(t, "", none.Long())
+}
+
+func TestTranslation_attributes(t *testing.T) {
+	// The deprecated ToolTip field is stored as the tooltip attribute
+	tr := Translation{KeyPath: "topbar.profile", ToolTip: "Your profile", Attributes: Attributes{AttrShort: "Me"}}
+	assert.Equal(t, Attributes{AttrTooltip: "Your profile", AttrShort: "Me"}, tr.attributes())
+	assert.Equal(t, Attributes{AttrShort: "Me"}, tr.Attributes, "the translation's own map must not change")
+
+	// Attributes takes precedence over ToolTip
+	tr.Attributes = Attributes{AttrTooltip: "Open your profile"}
+	assert.Equal(t, "Open your profile", tr.attributes().Tooltip())
+
+	// Rows without attributes still store an object
+	assert.NotNil(t, Translation{}.attributes())
+}
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"iter"
+)
+
+// DefaultChunkSize is the number of translations committed per transaction
+// when BulkOptions.ChunkSize is not set.
+const DefaultChunkSize = 1000
+
+// BulkOptions configures BulkUpsertTranslations.
+type BulkOptions struct {
+	// ChunkSize is the number of translations copied and committed per
+	// transaction. Zero or negative means DefaultChunkSize.
+	ChunkSize int
+	// Skip is the number of leading translations to skip. Pass the count
+	// returned by an interrupted BulkUpsertTranslations to resume after its
+	// last committed chunk.
+	Skip int
+	// Progress, if set, is called after each chunk is committed.
+	Progress func(BulkProgress)
+	// SourceLang, if set, stamps translations without a SourceHash as
+	// WithSourceLang does for UpsertTranslations.
+	SourceLang string
+}
+
+// BulkProgress reports the state of a bulk import after a committed chunk.
+type BulkProgress struct {
+	Chunk     int // 1-based number of the chunk just committed
+	Committed int // translations committed so far, including skipped ones
+}
+
+// BulkUpsertTranslations upserts translations from an iterator in chunks,
+// committing each chunk in its own transaction. It returns the number of
+// translations committed, including opts.Skip, so that after a failure the
+// import can be resumed by passing the returned count as opts.Skip.
+func BulkUpsertTranslations(ctx context.Context, conn *pgx.Conn, translations iter.Seq[Translation], opts BulkOptions) (int, error) {
+	committed := max(opts.Skip, 0)
+	chunk := 0
+	err := forEachChunk(translations, opts.ChunkSize, opts.Skip, func(batch []Translation) error {
+		chunk++
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("chunk %d: failed to begin transaction: %w", chunk, err)
+		}
+		defer tx.Rollback(ctx)
+
+		if err := copyAndUpsert(ctx, tx, batch, opts.SourceLang); err != nil {
+			return fmt.Errorf("chunk %d: %w", chunk, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("chunk %d: failed to commit: %w", chunk, err)
+		}
+
+		committed += len(batch)
+		if opts.Progress != nil {
+			opts.Progress(BulkProgress{Chunk: chunk, Committed: committed})
+		}
+		return nil
+	})
+	return committed, err
+}
+
+// forEachChunk skips the first skip translations and calls fn with
+// consecutive batches of at most size translations. It stops at the first
+// error returned by fn. The batch slice is reused between calls.
+func forEachChunk(translations iter.Seq[Translation], size, skip int, fn func([]Translation) error) error {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	batch := make([]Translation, 0, size)
+	var err error
+	for t := range translations {
+		if skip > 0 {
+			skip--
+			continue
+		}
+		batch = append(batch, t)
+		if len(batch) < size {
+			continue
+		}
+		if err = fn(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+	}
+	if len(batch) > 0 {
+		err = fn(batch)
+	}
+	return err
+}
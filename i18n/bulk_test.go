@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"testing"
+)
+
+func makeTranslations(n int) []Translation {
+	translations := make([]Translation, n)
+	for i := range translations {
+		translations[i] = Translation{Lang: "en", KeyPath: string(rune('a' + i)), Value: "v"}
+	}
+	return translations
+}
+
+func TestForEachChunk(t *testing.T) {
+	translations := makeTranslations(7)
+
+	var sizes []int
+	var keys []string
+	err := forEachChunk(slices.Values(translations), 3, 0, func(batch []Translation) error {
+		sizes = append(sizes, len(batch))
+		for _, tr := range batch {
+			keys = append(keys, tr.KeyPath)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 3, 1}, sizes)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, keys)
+}
+
+func TestForEachChunk_Skip(t *testing.T) {
+	translations := makeTranslations(7)
+
+	var keys []string
+	err := forEachChunk(slices.Values(translations), 3, 3, func(batch []Translation) error {
+		for _, tr := range batch {
+			keys = append(keys, tr.KeyPath)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d", "e", "f", "g"}, keys)
+}
+
+func TestForEachChunk_StopsOnError(t *testing.T) {
+	translations := makeTranslations(7)
+	failure := errors.New("boom")
+
+	calls := 0
+	err := forEachChunk(slices.Values(translations), 3, 0, func(batch []Translation) error {
+		calls++
+		if calls == 2 {
+			return failure
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, 2, calls)
+}
+
+func TestForEachChunk_DefaultSize(t *testing.T) {
+	translations := makeTranslations(5)
+
+	var sizes []int
+	err := forEachChunk(slices.Values(translations), 0, 0, func(batch []Translation) error {
+		sizes = append(sizes, len(batch))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5}, sizes)
+}
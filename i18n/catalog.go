@@ -0,0 +1,90 @@
+package i18n
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"io/fs"
+)
+
+// Catalog is an in-memory set of global translations, typically defaults
+// embedded in the binary with go:embed. It can seed the database at startup
+// and serve lookups when the database is unreachable.
+type Catalog struct {
+	translations []Translation
+	index        map[catalogKey]string
+}
+
+type catalogKey struct {
+	namespace string
+	lang      string
+	keyPath   string
+}
+
+// NewCatalog builds a catalog from translations. Tenant, user and scope
+// overrides are ignored; later translations for the same namespace, key and language win.
+func NewCatalog(translations []Translation) *Catalog {
+	c := &Catalog{index: make(map[catalogKey]string, len(translations))}
+	positions := make(map[catalogKey]int, len(translations))
+	for _, t := range translations {
+		if t.TenantID != nil || t.UserID != nil || t.Scope != "" {
+			continue
+		}
+		k := catalogKey{namespace: t.Namespace, lang: t.Lang, keyPath: t.KeyPath}
+		if i, exists := positions[k]; exists {
+			c.translations[i] = t
+		} else {
+			positions[k] = len(c.translations)
+			c.translations = append(c.translations, t)
+		}
+		c.index[k] = t.Value
+	}
+	return c
+}
+
+// LoadCatalogFS loads every catalog file in fsys matching the patterns, as
+// LoadDir does, into a Catalog.
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	defaults, err := i18n.LoadCatalogFS(locales, "locales/{lang}/{namespace}.json")
+func LoadCatalogFS(fsys fs.FS, patterns ...string) (*Catalog, error) {
+	translations, err := LoadDir(fsys, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(translations), nil
+}
+
+// Lookup returns the value stored for keyPath and lang. Without WithNamespace
+// it searches the default namespace.
+func (c *Catalog) Lookup(keyPath, lang string, opts ...LookupOption) (string, bool) {
+	o := newLookupOptions(opts)
+	value, ok := c.index[catalogKey{namespace: o.namespace, lang: lang, keyPath: keyPath}]
+	return value, ok
+}
+
+// Translations returns the catalog contents, e.g. to pass to SeedTranslations.
+func (c *Catalog) Translations() []Translation {
+	return c.translations
+}
+
+// Seed inserts the catalog's translations that are missing from the database.
+func (c *Catalog) Seed(ctx context.Context, conn *pgx.Conn) error {
+	return SeedTranslations(ctx, conn, c.translations)
+}
+
+// GetTranslationWithFallback behaves like GetTranslation but answers from
+// fallback when the database lookup fails, whether because the database is
+// unreachable or because the key has not been stored yet. The database error
+// is returned if fallback does not have the key either.
+func GetTranslationWithFallback(ctx context.Context, conn *pgx.Conn, fallback *Catalog, userID *string, keyPath, lang string, opts ...LookupOption) (string, error) {
+	value, err := GetTranslation(ctx, conn, userID, keyPath, lang, opts...)
+	if err == nil || fallback == nil {
+		return value, err
+	}
+	if value, ok := fallback.Lookup(keyPath, lang, opts...); ok {
+		return value, nil
+	}
+	return "", err
+}
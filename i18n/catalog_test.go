@@ -0,0 +1,44 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadCatalogFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en/common.json":   {Data: []byte(`{"topbar": {"profile": "Profile"}}`)},
+		"locales/es/common.json":   {Data: []byte(`{"topbar": {"profile": "Perfil"}}`)},
+		"locales/en/checkout.json": {Data: []byte(`{"pay": "Pay now"}`)},
+	}
+
+	catalog, err := LoadCatalogFS(fsys, "locales/{lang}/{namespace}.json")
+	assert.NoError(t, err)
+	assert.Len(t, catalog.Translations(), 3)
+
+	value, ok := catalog.Lookup("topbar.profile", "es", WithNamespace("common"))
+	assert.True(t, ok)
+	assert.Equal(t, "Perfil", value)
+
+	value, ok = catalog.Lookup("pay", "en", WithNamespace("checkout"))
+	assert.True(t, ok)
+	assert.Equal(t, "Pay now", value)
+
+	_, ok = catalog.Lookup("pay", "en")
+	assert.False(t, ok)
+}
+
+func TestNewCatalog(t *testing.T) {
+	catalog := NewCatalog([]Translation{
+		{Lang: "en", KeyPath: "title", Value: "Title"},
+		{Lang: "en", KeyPath: "title", Value: "Newer title"},
+		{UserID: stringPtr("user-1"), Lang: "en", KeyPath: "title", Value: "Mine"},
+		{TenantID: stringPtr("tenant-1"), Lang: "en", KeyPath: "title", Value: "Ours"},
+	})
+
+	value, ok := catalog.Lookup("title", "en")
+	assert.True(t, ok)
+	assert.Equal(t, "Newer title", value)
+	assert.Equal(t, []Translation{{Lang: "en", KeyPath: "title", Value: "Newer title"}}, catalog.Translations())
+}
@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+// LangCoverage is how complete one language is in one namespace, relative to
+// the keys of the source language.
+type LangCoverage struct {
+	Namespace         string  `json:"namespace"`
+	Lang              string  `json:"lang"`
+	Total             int     `json:"total"`      // keys of the source language
+	Translated        int     `json:"translated"` // keys with a value that differs from the source
+	Identical         int     `json:"identical"`  // keys whose value equals the source, often left untranslated
+	Missing           int     `json:"missing"`    // keys without a value
+	TranslatedPercent float64 `json:"translated_percent"`
+	IdenticalPercent  float64 `json:"identical_percent"`
+	MissingPercent    float64 `json:"missing_percent"`
+}
+
+// CoverageReport lists the coverage of every language but the source one,
+// per namespace.
+type CoverageReport struct {
+	SourceLang string         `json:"source_lang"`
+	TenantID   *string        `json:"tenant_id,omitempty"` // nil = global translations only
+	Languages  []LangCoverage `json:"languages"`
+}
+
+// Coverage reports, for each namespace and each language stored besides
+// sourceLang, how many of the source language's keys are translated, missing
+// or identical to the source. Only global translations count, unless
+// WithTenant is given: the tenant's overrides then count as well, for the
+// source language too. User and scope overrides and experiment variants are
+// ignored, and so are values not approved or not in effect now. Only
+// WithTenant is honoured among the options. The report marshals to JSON as
+// is and renders as a table with WriteText.
+func Coverage(ctx context.Context, conn *pgx.Conn, sourceLang string, opts ...LookupOption) (*CoverageReport, error) {
+	o := newLookupOptions(opts)
+	report := &CoverageReport{SourceLang: sourceLang, TenantID: o.tenantID, Languages: []LangCoverage{}}
+
+	query := `
+		WITH effective AS (
+			SELECT DISTINCT ON (namespace, key_path, lang) namespace, key_path, lang, approved_value AS value
+			FROM ui_translations
+			WHERE user_id IS NULL AND scope = '' AND variant = '' AND (tenant_id IS NULL OR tenant_id = $2::uuid)
+			AND approved_value IS NOT NULL AND ` + activeSQL("", "NOW()") + `
+			ORDER BY namespace, key_path, lang, tenant_id NULLS LAST, ` + latestEffective + `
+		),
+		source AS (SELECT namespace, key_path, value FROM effective WHERE lang = $1),
+		langs AS (SELECT DISTINCT lang FROM effective WHERE lang <> $1)
+		SELECT s.namespace, l.lang, COUNT(*),
+			COUNT(t.value) FILTER (WHERE t.value <> s.value),
+			COUNT(t.value) FILTER (WHERE t.value = s.value)
+		FROM source s CROSS JOIN langs l
+		LEFT JOIN effective t ON t.namespace = s.namespace AND t.key_path = s.key_path AND t.lang = l.lang
+		GROUP BY s.namespace, l.lang
+		ORDER BY s.namespace, l.lang
+	`
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, sourceLang, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c LangCoverage
+			if err = rows.Scan(&c.Namespace, &c.Lang, &c.Total, &c.Translated, &c.Identical); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			report.Languages = append(report.Languages, c.withMissing())
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// withMissing fills in Missing and the percentages from the counts.
+func (c LangCoverage) withMissing() LangCoverage {
+	c.Missing = c.Total - c.Translated - c.Identical
+	c.TranslatedPercent = percent(c.Translated, c.Total)
+	c.IdenticalPercent = percent(c.Identical, c.Total)
+	c.MissingPercent = percent(c.Missing, c.Total)
+	return c
+}
+
+// percent returns n out of total as a percentage rounded to one decimal.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*1000/float64(total)) / 10
+}
+
+// WriteText renders the report as an aligned table for review.
+func (r *CoverageReport) WriteText(w io.Writer) error {
+	header := "source " + r.SourceLang
+	if r.TenantID != nil {
+		header += ", tenant " + *r.TenantID
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tLANG\tKEYS\tTRANSLATED\tIDENTICAL\tMISSING")
+	for _, c := range r.Languages {
+		namespace := c.Namespace
+		if namespace == "" {
+			namespace = "(default)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d (%.1f%%)\t%d (%.1f%%)\t%d (%.1f%%)\n", namespace, c.Lang, c.Total,
+			c.Translated, c.TranslatedPercent, c.Identical, c.IdenticalPercent, c.Missing, c.MissingPercent)
+	}
+	return tw.Flush()
+}
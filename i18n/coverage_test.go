@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func sampleCoverageReport() *CoverageReport {
+	return &CoverageReport{
+		SourceLang: "en",
+		Languages: []LangCoverage{
+			LangCoverage{Lang: "de", Total: 3, Translated: 2, Identical: 1}.withMissing(),
+			LangCoverage{Namespace: "checkout", Lang: "de", Total: 8, Translated: 5}.withMissing(),
+		},
+	}
+}
+
+func TestLangCoverage_withMissing(t *testing.T) {
+	c := LangCoverage{Total: 3, Translated: 1, Identical: 1}.withMissing()
+	assert.Equal(t, 1, c.Missing)
+	assert.Equal(t, 33.3, c.TranslatedPercent)
+	assert.Equal(t, 33.3, c.MissingPercent)
+
+	empty := LangCoverage{}.withMissing()
+	assert.Equal(t, 0.0, empty.MissingPercent)
+}
+
+func TestCoverageReport_WriteText(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, sampleCoverageReport().WriteText(&buf))
+	assert.Equal(t, `source en
+NAMESPACE  LANG  KEYS  TRANSLATED  IDENTICAL  MISSING
+(default)  de    3     2 (66.7%)   1 (33.3%)  0 (0.0%)
+checkout   de    8     5 (62.5%)   0 (0.0%)   3 (37.5%)
+`, buf.String())
+}
+
+func TestCoverageReport_JSON(t *testing.T) {
+	data, err := json.Marshal(sampleCoverageReport())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "source_lang": "en",
+  "languages": [
+    {"namespace": "", "lang": "de", "total": 3, "translated": 2, "identical": 1, "missing": 0,
+     "translated_percent": 66.7, "identical_percent": 33.3, "missing_percent": 0},
+    {"namespace": "checkout", "lang": "de", "total": 8, "translated": 5, "identical": 0, "missing": 3,
+     "translated_percent": 62.5, "identical_percent": 0, "missing_percent": 37.5}
+  ]
+}`, string(data))
+}
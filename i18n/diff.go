@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"text/tabwriter"
+)
+
+// Customization is an override whose value differs from the global
+// translation of the same key.
+type Customization struct {
+	KeyPath  string  `json:"key_path"`
+	Level    string  `json:"level"` // "user", "scope" or "tenant"
+	TenantID *string `json:"tenant_id,omitempty"`
+	UserID   *string `json:"user_id,omitempty"`
+	Scope    string  `json:"scope,omitempty"`
+	Value    string  `json:"value"`
+	Global   *string `json:"global"` // nil if the key has no global translation
+}
+
+// DiffReport lists the customizations in effect for one language and namespace.
+type DiffReport struct {
+	Lang           string          `json:"lang"`
+	Namespace      string          `json:"namespace"`
+	Customizations []Customization `json:"customizations"`
+}
+
+// DiffOverrides returns, for each key overridden for the user, scopes or
+// tenant given, the winning override next to the global value. Overrides
+// identical to the global value are left out. The report marshals to JSON
+// as is and renders as a table with WriteText.
+func DiffOverrides(ctx context.Context, conn *pgx.Conn, lang string, userID *string, opts ...LookupOption) (*DiffReport, error) {
+	o := newLookupOptions(opts)
+	report := &DiffReport{Lang: lang, Namespace: o.namespace, Customizations: []Customization{}}
+
+	var args queryArgs
+	levels := scopeChain(userID, o, ExportOverridesOnly, &args)
+	if len(levels) == 0 {
+		return report, nil
+	}
+	langArg, namespaceArg, at := args.add(lang), args.add(o.namespace), o.atSQL(&args)
+	column := o.valueColumn()
+	query := `
+		WITH o AS (
+			SELECT DISTINCT ON (key_path) key_path, ` + scopePrecedence(levels) + ` AS level,
+				tenant_id::text AS tenant_id, user_id::text AS user_id, scope, ` + column + ` AS value
+			FROM ui_translations
+			WHERE lang = ` + langArg + ` AND namespace = ` + namespaceArg + ` AND ` + scopeFilter(levels) + `
+			AND ` + column + ` IS NOT NULL AND ` + activeSQL("", at) + ` AND ` + o.variantSQL(&args) + `
+			ORDER BY key_path, level, ` + controlLast + `, ` + latestEffective + `
+		)
+		SELECT o.key_path, o.level, o.tenant_id, o.user_id, o.scope, o.value, g.value
+		FROM o LEFT JOIN LATERAL (
+			SELECT ` + column + ` AS value FROM ui_translations g
+			WHERE g.key_path = o.key_path AND g.lang = ` + langArg + ` AND g.namespace = ` + namespaceArg + `
+			AND g.tenant_id IS NULL AND g.user_id IS NULL AND g.scope = '' AND g.variant = ''
+			AND g.` + column + ` IS NOT NULL AND ` + activeSQL("g", at) + `
+			ORDER BY g.` + latestEffective + `
+			LIMIT 1
+		) g ON TRUE
+		WHERE g.value IS DISTINCT FROM o.value
+		ORDER BY o.key_path
+	`
+
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c Customization
+			var level int
+			if err = rows.Scan(&c.KeyPath, &level, &c.TenantID, &c.UserID, &c.Scope, &c.Value, &c.Global); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			c.Level = levels[level].name
+			report.Customizations = append(report.Customizations, c)
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Translations converts the customizations back into translations for lang
+// and namespace, e.g. to upsert them into another environment.
+func (r *DiffReport) Translations() []Translation {
+	translations := make([]Translation, 0, len(r.Customizations))
+	for _, c := range r.Customizations {
+		translations = append(translations, Translation{
+			TenantID:  c.TenantID,
+			UserID:    c.UserID,
+			Scope:     c.Scope,
+			Namespace: r.Namespace,
+			Lang:      r.Lang,
+			KeyPath:   c.KeyPath,
+			Value:     c.Value,
+		})
+	}
+	return translations
+}
+
+// WriteText renders the report as an aligned table for review.
+func (r *DiffReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "lang %s, namespace %q: %d customized\n", r.Lang, r.Namespace, len(r.Customizations)); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tLEVEL\tOVERRIDE\tGLOBAL")
+	for _, c := range r.Customizations {
+		level := c.Level
+		if c.Scope != "" {
+			level += " " + c.Scope
+		}
+		global := "(none)"
+		if c.Global != nil {
+			global = fmt.Sprintf("%q", *c.Global)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%q\t%s\n", c.KeyPath, level, c.Value, global)
+	}
+	return tw.Flush()
+}
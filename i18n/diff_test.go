@@ -0,0 +1,50 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func sampleDiffReport() *DiffReport {
+	return &DiffReport{
+		Lang:      "en",
+		Namespace: "checkout",
+		Customizations: []Customization{
+			{KeyPath: "cart.title", Level: "tenant", TenantID: stringPtr("org-1"), Value: "Basket", Global: stringPtr("Cart")},
+			{KeyPath: "cart.promo", Level: "scope", TenantID: stringPtr("org-1"), Scope: "team:7", Value: "Team deal"},
+		},
+	}
+}
+
+func TestDiffReport_WriteText(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, sampleDiffReport().WriteText(&buf))
+	assert.Equal(t, `lang en, namespace "checkout": 2 customized
+KEY         LEVEL         OVERRIDE     GLOBAL
+cart.title  tenant        "Basket"     "Cart"
+cart.promo  scope team:7  "Team deal"  (none)
+`, buf.String())
+}
+
+func TestDiffReport_JSON(t *testing.T) {
+	data, err := json.Marshal(sampleDiffReport())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "lang": "en",
+  "namespace": "checkout",
+  "customizations": [
+    {"key_path": "cart.title", "level": "tenant", "tenant_id": "org-1", "value": "Basket", "global": "Cart"},
+    {"key_path": "cart.promo", "level": "scope", "tenant_id": "org-1", "scope": "team:7", "value": "Team deal", "global": null}
+  ]
+}`, string(data))
+}
+
+func TestDiffReport_Translations(t *testing.T) {
+	translations := sampleDiffReport().Translations()
+	assert.Equal(t, []Translation{
+		{TenantID: stringPtr("org-1"), Namespace: "checkout", Lang: "en", KeyPath: "cart.title", Value: "Basket"},
+		{TenantID: stringPtr("org-1"), Scope: "team:7", Namespace: "checkout", Lang: "en", KeyPath: "cart.promo", Value: "Team deal"},
+	}, translations)
+}
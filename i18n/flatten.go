@@ -3,6 +3,7 @@ package i18n
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 )
 
@@ -40,6 +41,20 @@ func LoadAndFlatten(filePath string) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return unmarshalAndFlatten(bytes)
+}
+
+// LoadAndFlattenFS reads a JSON file from fsys, such as an embed.FS, and
+// flattens its contents.
+func LoadAndFlattenFS(fsys fs.FS, name string) (map[string]string, error) {
+	bytes, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAndFlatten(bytes)
+}
+
+func unmarshalAndFlatten(bytes []byte) (map[string]string, error) {
 	var nested map[string]interface{}
 	if err := json.Unmarshal(bytes, &nested); err != nil {
 		return nil, err
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"testing/fstest"
 )
 
 func TestFlattenJSON(t *testing.T) {
@@ -192,3 +193,26 @@ func TestLoadAndFlatten_FileNotFound(t *testing.T) {
 		t.Errorf("expected error for nonexistent file, got nil")
 	}
 }
+
+func TestLoadAndFlattenFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"greetings": {"hello": "Hello!"}}`)},
+		"invalid.json":    {Data: []byte(`{invalid json}`)},
+	}
+
+	result, err := LoadAndFlattenFS(fsys, "locales/en.json")
+	if err != nil {
+		t.Fatalf("LoadAndFlattenFS returned error: %v", err)
+	}
+	expected := map[string]string{"greetings.hello": "Hello!"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if _, err = LoadAndFlattenFS(fsys, "invalid.json"); err == nil {
+		t.Errorf("expected error for invalid JSON, got nil")
+	}
+	if _, err = LoadAndFlattenFS(fsys, "missing.json"); err == nil {
+		t.Errorf("expected error for nonexistent file, got nil")
+	}
+}
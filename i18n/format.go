@@ -0,0 +1,448 @@
+package i18n
+
+import (
+	"fmt"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format renders the message for lang with args. Numbers are formatted with
+// lang's digits and separators; plural and selectordinal cases are chosen by
+// lang's CLDR plural rules (see PluralCategory). An argument missing from
+// args is rendered as its placeholder, e.g. "{name}", so a broken call
+// degrades visibly instead of failing.
+//
+// Number arguments accept any Go integer or float type or a numeric string.
+// Date and time arguments accept a time.Time or a number of milliseconds since
+// the Unix epoch, and use English month and day names.
+func (m *Message) Format(lang string, args map[string]any) string {
+	f := &formatter{lang: lang, printer: printerFor(lang), args: args}
+	var b strings.Builder
+	f.render(&b, m, nil)
+	return b.String()
+}
+
+type formatter struct {
+	lang    string
+	printer *message.Printer
+	args    map[string]any
+}
+
+var printers sync.Map // lang → *message.Printer
+
+func printerFor(lang string) *message.Printer {
+	if p, ok := printers.Load(lang); ok {
+		return p.(*message.Printer)
+	}
+	p, _ := printers.LoadOrStore(lang, message.NewPrinter(language.Make(lang)))
+	return p.(*message.Printer)
+}
+
+// render writes m to b. pound is the value "#" stands for, nil outside plurals.
+func (f *formatter) render(b *strings.Builder, m *Message, pound *float64) {
+	for _, part := range m.parts {
+		switch part := part.(type) {
+		case textPart:
+			b.WriteString(string(part))
+		case poundPart:
+			if pound == nil {
+				b.WriteByte('#')
+			} else {
+				b.WriteString(numberStyle{}.format(f.printer, *pound))
+			}
+		case argPart:
+			v, ok := f.args[part.name]
+			if !ok {
+				b.WriteString("{" + part.name + "}")
+				continue
+			}
+			b.WriteString(f.argument(part, v))
+		case pluralPart:
+			v, ok := f.args[part.name]
+			if !ok {
+				b.WriteString("{" + part.name + "}")
+				continue
+			}
+			c, n := f.pluralCase(part, v)
+			f.render(b, c, n)
+		case selectPart:
+			v, ok := f.args[part.name]
+			if !ok {
+				b.WriteString("{" + part.name + "}")
+				continue
+			}
+			f.render(b, selectCaseFor(part, fmt.Sprint(v)), pound)
+		}
+	}
+}
+
+func (f *formatter) argument(part argPart, v any) string {
+	switch part.kind {
+	case "number":
+		if n, ok := toNumber(v); ok {
+			return part.number.format(f.printer, n)
+		}
+	case "date", "time":
+		if t, ok := toTime(v); ok {
+			return t.Format(part.layout)
+		}
+	default:
+		switch v := v.(type) {
+		case string:
+			return v
+		case time.Time:
+			return v.Format(dateStyles["medium"])
+		}
+		if n, ok := toNumber(v); ok {
+			return numberStyle{}.format(f.printer, n)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// pluralCase picks the case of part for v: an exact "=N" match first, then
+// the plural category of v minus the offset, then "other". It returns the
+// case and the value "#" stands for in it.
+func (f *formatter) pluralCase(part pluralPart, v any) (*Message, *float64) {
+	var other *Message
+	n, isNumber := toNumber(v)
+	if isNumber {
+		for _, c := range part.cases {
+			if c.exact != nil && *c.exact == n {
+				n -= part.offset
+				return c.message, &n
+			}
+		}
+		n -= part.offset
+	}
+	category := ""
+	if isNumber {
+		category = pluralCategory(f.lang, n, visibleFractionDigits(v, n), part.ordinal)
+	}
+	for _, c := range part.cases {
+		if c.selector == category {
+			return c.message, &n
+		}
+		if c.selector == "other" {
+			other = c.message
+		}
+	}
+	if !isNumber {
+		return other, nil
+	}
+	return other, &n
+}
+
+func selectCaseFor(part selectPart, v string) *Message {
+	var other *Message
+	for _, c := range part.cases {
+		if c.selector == v {
+			return c.message
+		}
+		if c.selector == "other" {
+			other = c.message
+		}
+	}
+	return other
+}
+
+func toNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toTime(v any) (time.Time, bool) {
+	if t, ok := v.(time.Time); ok {
+		return t, true
+	}
+	if n, ok := toNumber(v); ok {
+		return time.UnixMilli(int64(n)), true
+	}
+	return time.Time{}, false
+}
+
+// numberStyle is the parsed style of a number argument.
+type numberStyle struct {
+	percent      bool
+	percentUnits bool // the skeleton's percent: n is already in percent
+	currency     *currency.Unit
+	scale        float64 // 0 = 1
+	hasPrecision bool
+	minFraction  int
+	maxFraction  int
+	noGrouping   bool
+	signAlways   bool
+}
+
+var fractionPrecision = regexp.MustCompile(`^\.(0*)(#*)$`)
+
+// parseNumberStyle parses "", "integer", "percent" or a "::" number skeleton
+// made of the tokens percent (%), scale/N, currency/XXX, precision-integer,
+// .00 / .0# style fraction precision, group-off (,_) and sign-always (+!).
+func parseNumberStyle(style string) (numberStyle, error) {
+	var s numberStyle
+	switch style {
+	case "":
+		return s, nil
+	case "integer":
+		s.hasPrecision = true
+		return s, nil
+	case "percent":
+		s.percent = true
+		return s, nil
+	}
+	skeleton, ok := strings.CutPrefix(style, "::")
+	if !ok {
+		return s, fmt.Errorf("unsupported number style %q", style)
+	}
+	for _, token := range strings.Fields(skeleton) {
+		switch {
+		case token == "percent" || token == "%":
+			// Unlike the percent style, the skeleton does not multiply by
+			// 100; "percent scale/100" does.
+			s.percent, s.percentUnits = true, true
+		case token == "precision-integer":
+			s.hasPrecision, s.minFraction, s.maxFraction = true, 0, 0
+		case token == "group-off" || token == ",_":
+			s.noGrouping = true
+		case token == "sign-always" || token == "+!":
+			s.signAlways = true
+		case strings.HasPrefix(token, "scale/"):
+			scale, err := strconv.ParseFloat(token[len("scale/"):], 64)
+			if err != nil || scale == 0 {
+				return s, fmt.Errorf("invalid number skeleton token %q", token)
+			}
+			s.scale = scale
+		case strings.HasPrefix(token, "currency/"):
+			unit, err := currency.ParseISO(token[len("currency/"):])
+			if err != nil {
+				return s, fmt.Errorf("invalid number skeleton token %q", token)
+			}
+			s.currency = &unit
+		default:
+			m := fractionPrecision.FindStringSubmatch(token)
+			if m == nil {
+				return s, fmt.Errorf("unsupported number skeleton token %q", token)
+			}
+			s.hasPrecision = true
+			s.minFraction = len(m[1])
+			s.maxFraction = len(m[1]) + len(m[2])
+		}
+	}
+	return s, nil
+}
+
+func (s numberStyle) format(p *message.Printer, n float64) string {
+	if s.scale != 0 {
+		n *= s.scale
+	}
+	if s.percentUnits {
+		n /= 100
+	}
+	var opts []number.Option
+	switch {
+	case s.hasPrecision:
+		opts = append(opts, number.MinFractionDigits(s.minFraction), number.MaxFractionDigits(s.maxFraction))
+	case !s.percent:
+		opts = append(opts, number.MaxFractionDigits(3))
+	}
+	if s.noGrouping {
+		opts = append(opts, number.NoSeparator())
+	}
+
+	var formatted string
+	switch {
+	case s.currency != nil:
+		formatted = p.Sprint(currency.Symbol(s.currency.Amount(n)))
+	case s.percent:
+		formatted = p.Sprint(number.Percent(n, opts...))
+	default:
+		formatted = p.Sprint(number.Decimal(n, opts...))
+	}
+	if s.signAlways && n >= 0 {
+		formatted = "+" + formatted
+	}
+	return formatted
+}
+
+// dateStyles and timeStyles are the Go layouts of the predefined date and
+// time styles.
+var (
+	dateStyles = map[string]string{
+		"short":  "1/2/06",
+		"medium": "Jan 2, 2006",
+		"long":   "January 2, 2006",
+		"full":   "Monday, January 2, 2006",
+	}
+	timeStyles = map[string]string{
+		"short":  "3:04 PM",
+		"medium": "3:04:05 PM",
+		"long":   "3:04:05 PM MST",
+		"full":   "3:04:05 PM MST",
+	}
+)
+
+// dateLayout returns the Go layout for a date or time argument's style: one
+// of short, medium (the default), long and full, or a "::" date skeleton.
+func dateLayout(kind, style string) (string, error) {
+	if skeleton, ok := strings.CutPrefix(style, "::"); ok {
+		return skeletonLayout(strings.TrimSpace(skeleton))
+	}
+	if style == "" {
+		style = "medium"
+	}
+	styles := dateStyles
+	if kind == "time" {
+		styles = timeStyles
+	}
+	layout, ok := styles[style]
+	if !ok {
+		return "", fmt.Errorf("unsupported %s style %q", kind, style)
+	}
+	return layout, nil
+}
+
+// skeletonLayout turns a date skeleton such as "yMMMd" or "EEEEjmm" into a Go
+// layout. As in ICU the skeleton only names the fields; their order and
+// punctuation follow the English patterns.
+func skeletonLayout(skeleton string) (string, error) {
+	fields := map[rune]int{}
+	for _, r := range skeleton {
+		switch r {
+		case 'L':
+			r = 'M'
+		case 'k':
+			r = 'H'
+		case 'K':
+			r = 'h'
+		case 'c':
+			r = 'E'
+		case 'v', 'V', 'O', 'Z':
+			r = 'z'
+		}
+		if !strings.ContainsRune("yMdEHhjmsaz", r) {
+			return "", fmt.Errorf("unsupported date skeleton field %q", string(r))
+		}
+		fields[r]++
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty date skeleton")
+	}
+
+	year, day := "2006", "2"
+	if fields['y'] == 2 {
+		year = "06"
+	}
+	if fields['d'] == 2 {
+		day = "02"
+	}
+	var date string
+	if m := fields['M']; m >= 3 {
+		date = "Jan"
+		if m >= 4 {
+			date = "January"
+		}
+		if fields['d'] > 0 {
+			date += " " + day
+		}
+		if fields['y'] > 0 {
+			if fields['d'] > 0 {
+				date += ","
+			}
+			date += " " + year
+		}
+	} else {
+		var numeric []string
+		if m == 1 {
+			numeric = append(numeric, "1")
+		} else if m == 2 {
+			numeric = append(numeric, "01")
+		}
+		if fields['d'] > 0 {
+			numeric = append(numeric, day)
+		}
+		if fields['y'] > 0 {
+			numeric = append(numeric, year)
+		}
+		date = strings.Join(numeric, "/")
+	}
+	if e := fields['E']; e > 0 {
+		weekday := "Mon"
+		if e >= 4 {
+			weekday = "Monday"
+		}
+		date = joinNonEmpty(", ", weekday, date)
+	}
+
+	var clock []string
+	twelveHour := fields['h'] > 0 || fields['j'] > 0
+	switch {
+	case fields['H'] > 0:
+		clock = append(clock, "15")
+	case fields['h'] == 2 || fields['j'] == 2:
+		clock = append(clock, "03")
+	case twelveHour:
+		clock = append(clock, "3")
+	}
+	if fields['m'] > 0 {
+		clock = append(clock, "04")
+	}
+	if fields['s'] > 0 {
+		clock = append(clock, "05")
+	}
+	timeOfDay := strings.Join(clock, ":")
+	if twelveHour {
+		timeOfDay += " PM"
+	}
+	if fields['z'] > 0 {
+		timeOfDay = joinNonEmpty(" ", timeOfDay, "MST")
+	}
+
+	return joinNonEmpty(", ", date, timeOfDay), nil
+}
+
+// joinNonEmpty joins the non-empty elements with sep.
+func joinNonEmpty(sep string, elems ...string) string {
+	var nonEmpty []string
+	for _, e := range elems {
+		if e != "" {
+			nonEmpty = append(nonEmpty, e)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
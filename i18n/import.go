@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+)
+
+// Import stages translations from many files and languages and writes them
+// to the database atomically with Commit. Either every staged translation is
+// stored or, on any validation or database error, none is.
+type Import struct {
+	translations []Translation
+}
+
+// NewImport returns an empty import session.
+func NewImport() *Import {
+	return &Import{}
+}
+
+// Add stages translations.
+func (imp *Import) Add(translations ...Translation) {
+	imp.translations = append(imp.translations, translations...)
+}
+
+// AddMap stages a flattened catalog, as returned by LoadAndFlatten, for lang.
+func (imp *Import) AddMap(lang string, userID *string, flatMap map[string]string) {
+	for keyPath, value := range flatMap {
+		imp.translations = append(imp.translations, Translation{
+			UserID:  userID,
+			Lang:    lang,
+			KeyPath: keyPath,
+			Value:   value,
+		})
+	}
+}
+
+// AddFile loads and flattens a JSON file and stages its contents for lang.
+func (imp *Import) AddFile(filePath, lang string, userID *string) error {
+	flatMap, err := LoadAndFlatten(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filePath, err)
+	}
+	imp.AddMap(lang, userID, flatMap)
+	return nil
+}
+
+// Len returns the number of staged translations.
+func (imp *Import) Len() int {
+	return len(imp.translations)
+}
+
+// Validate checks the staged translations without touching the database.
+// It reports every translation with a missing language or key and every key
+// staged more than once for the same tenant, user, scope, namespace and language.
+func (imp *Import) Validate() error {
+	seen := make(map[rowKey]struct{}, len(imp.translations))
+
+	var errs []error
+	for _, t := range imp.translations {
+		if t.Lang == "" {
+			errs = append(errs, fmt.Errorf("key %q: missing language", t.KeyPath))
+			continue
+		}
+		if t.KeyPath == "" {
+			errs = append(errs, fmt.Errorf("lang %q: missing key path", t.Lang))
+			continue
+		}
+		k := t.rowKey()
+		if _, dup := seen[k]; dup {
+			errs = append(errs, fmt.Errorf("lang %q: key %q staged more than once", t.Lang, t.KeyPath))
+			continue
+		}
+		seen[k] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+// Commit validates the staged translations and upserts all of them in a
+// single transaction, rolling back on the first error. Only WithSourceLang is
+// honoured among the options.
+func (imp *Import) Commit(ctx context.Context, conn *pgx.Conn, opts ...LookupOption) error {
+	if err := imp.Validate(); err != nil {
+		return fmt.Errorf("import validation failed: %w", err)
+	}
+	if len(imp.translations) == 0 {
+		return nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = copyAndUpsert(ctx, tx, imp.translations, newLookupOptions(opts).sourceLang); err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestImport_AddFile(t *testing.T) {
+	en := createTempJSONFile(t, map[string]interface{}{
+		"topbar": map[string]interface{}{"profile": "Profile"},
+	})
+
+	imp := NewImport()
+	assert.NoError(t, imp.AddFile(en, "en", nil))
+	assert.Error(t, imp.AddFile("non_existent_file.json", "es", nil))
+	assert.Equal(t, 1, imp.Len())
+	assert.NoError(t, imp.Validate())
+}
+
+func TestImport_Validate(t *testing.T) {
+	imp := NewImport()
+	imp.AddMap("en", nil, map[string]string{"topbar.profile": "Profile"})
+	imp.AddMap("es", nil, map[string]string{"topbar.profile": "Perfil"})
+	imp.AddMap("en", stringPtr("user-1"), map[string]string{"topbar.profile": "My Profile"})
+	assert.NoError(t, imp.Validate())
+
+	imp.Add(
+		Translation{Lang: "en", KeyPath: "topbar.profile", Value: "Duplicate"},
+		Translation{Lang: "", KeyPath: "footer.contact", Value: "Contact"},
+		Translation{Lang: "ar", KeyPath: "", Value: "ملف"},
+	)
+	err := imp.Validate()
+	assert.ErrorContains(t, err, `key "topbar.profile" staged more than once`)
+	assert.ErrorContains(t, err, `key "footer.contact": missing language`)
+	assert.ErrorContains(t, err, `lang "ar": missing key path`)
+}
+
+func TestImport_ValidateNamespaces(t *testing.T) {
+	imp := NewImport()
+	imp.Add(
+		Translation{Namespace: "admin", Lang: "en", KeyPath: "title", Value: "Admin"},
+		Translation{Namespace: "checkout", Lang: "en", KeyPath: "title", Value: "Checkout"},
+	)
+	assert.NoError(t, imp.Validate())
+}
@@ -0,0 +1,184 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"unicode/utf8"
+)
+
+// KeyMetadata is the context translators get for a key, stored once per
+// namespace and key path in translation_keys.
+type KeyMetadata struct {
+	Namespace   string   `json:"namespace"`
+	KeyPath     string   `json:"key_path"`
+	Description string   `json:"description,omitempty"`
+	Notes       string   `json:"notes,omitempty"`      // developer notes
+	Screenshot  string   `json:"screenshot,omitempty"` // URL or path of a screenshot
+	MaxLength   int      `json:"max_length,omitempty"` // in characters; 0 = no limit
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Fits reports whether value respects the key's maximum length.
+func (m KeyMetadata) Fits(value string) bool {
+	return m.MaxLength <= 0 || utf8.RuneCountInString(value) <= m.MaxLength
+}
+
+// UpsertKeyMetadata inserts or replaces the metadata of each key in one
+// transaction.
+func UpsertKeyMetadata(ctx context.Context, conn *pgx.Conn, metadata []KeyMetadata) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const query = `
+		INSERT INTO translation_keys (namespace, key_path, description, notes, screenshot, max_length, tags, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, NOW())
+		ON CONFLICT (namespace, key_path)
+		DO UPDATE SET description = EXCLUDED.description, notes = EXCLUDED.notes, screenshot = EXCLUDED.screenshot,
+			max_length = EXCLUDED.max_length, tags = EXCLUDED.tags, updated_at = EXCLUDED.updated_at
+	`
+	for _, m := range metadata {
+		tags := m.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		if _, err = tx.Exec(ctx, query, m.Namespace, m.KeyPath, m.Description, m.Notes, m.Screenshot, m.MaxLength, tags); err != nil {
+			return fmt.Errorf("failed to upsert metadata for %q: %w", m.KeyPath, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetKeyMetadata returns the metadata of a key, or pgx.ErrNoRows if it has none.
+func GetKeyMetadata(ctx context.Context, conn *pgx.Conn, namespace, keyPath string) (KeyMetadata, error) {
+	m := KeyMetadata{Namespace: namespace, KeyPath: keyPath}
+	var maxLength *int
+	err := conn.QueryRow(ctx, `
+		SELECT description, notes, screenshot, max_length, tags FROM translation_keys
+		WHERE namespace = $1 AND key_path = $2
+	`, namespace, keyPath).Scan(&m.Description, &m.Notes, &m.Screenshot, &maxLength, &m.Tags)
+	if maxLength != nil {
+		m.MaxLength = *maxLength
+	}
+	return m, err
+}
+
+// ListKeyMetadata returns the metadata of every key in namespace, optionally
+// only keys carrying tag, ordered by key path.
+func ListKeyMetadata(ctx context.Context, conn *pgx.Conn, namespace, tag string) ([]KeyMetadata, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT key_path, description, notes, screenshot, max_length, tags FROM translation_keys
+		WHERE namespace = $1 AND ($2 = '' OR $2 = ANY(tags))
+		ORDER BY key_path
+	`, namespace, tag)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var metadata []KeyMetadata
+	for rows.Next() {
+		m := KeyMetadata{Namespace: namespace}
+		var maxLength *int
+		if err = rows.Scan(&m.KeyPath, &m.Description, &m.Notes, &m.Screenshot, &maxLength, &m.Tags); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if maxLength != nil {
+			m.MaxLength = *maxLength
+		}
+		metadata = append(metadata, m)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("row iteration error: %w", rows.Err())
+	}
+	return metadata, nil
+}
+
+// DeleteKeyMetadata removes the metadata of a key. Its translations are kept.
+func DeleteKeyMetadata(ctx context.Context, conn *pgx.Conn, namespace, keyPath string) error {
+	_, err := conn.Exec(ctx, `
+		DELETE FROM translation_keys WHERE namespace = $1 AND key_path = $2
+	`, namespace, keyPath)
+	return err
+}
+
+// TranslatorEntry is one key of a translator export: the source text, the
+// current translation if any, and the key's metadata if any.
+type TranslatorEntry struct {
+	KeyPath    string       `json:"key_path"`
+	Source     string       `json:"source"`
+	SourceHash string       `json:"source_hash"` // store as Translation.SourceHash with the translation
+	Target     *string      `json:"target"`
+	TooLong    bool         `json:"too_long,omitempty"` // Target exceeds Context.MaxLength
+	Context    *KeyMetadata `json:"context,omitempty"`
+}
+
+// TranslatorExport is a namespace's global strings prepared for translating
+// from SourceLang into TargetLang.
+type TranslatorExport struct {
+	SourceLang string            `json:"source_lang"`
+	TargetLang string            `json:"target_lang"`
+	Namespace  string            `json:"namespace"`
+	Entries    []TranslatorEntry `json:"entries"`
+}
+
+// ExportForTranslators returns every global key of the namespace (the default
+// one without WithNamespace) in sourceLang, alongside its targetLang value and
+// the description, notes, screenshot, maximum length and tags translators
+// need, as currently approved and in effect. Only WithNamespace is honoured
+// among the options.
+func ExportForTranslators(ctx context.Context, conn *pgx.Conn, sourceLang, targetLang string, opts ...LookupOption) (*TranslatorExport, error) {
+	o := newLookupOptions(opts)
+	export := &TranslatorExport{SourceLang: sourceLang, TargetLang: targetLang, Namespace: o.namespace, Entries: []TranslatorEntry{}}
+
+	rows, err := conn.Query(ctx, `
+		SELECT DISTINCT ON (s.key_path) s.key_path, s.approved_value, t.approved_value,
+			k.key_path IS NOT NULL, COALESCE(k.description, ''), COALESCE(k.notes, ''),
+			COALESCE(k.screenshot, ''), COALESCE(k.max_length, 0), COALESCE(k.tags, '{}')
+		FROM ui_translations s
+		LEFT JOIN LATERAL (
+			SELECT t.approved_value FROM ui_translations t
+			WHERE t.namespace = s.namespace AND t.key_path = s.key_path AND t.lang = $2
+			AND t.tenant_id IS NULL AND t.user_id IS NULL AND t.scope = '' AND t.variant = ''
+			AND t.approved_value IS NOT NULL AND `+activeSQL("t", "NOW()")+`
+			ORDER BY t.`+latestEffective+`
+			LIMIT 1
+		) t ON TRUE
+		LEFT JOIN translation_keys k ON k.namespace = s.namespace AND k.key_path = s.key_path
+		WHERE s.lang = $1 AND s.namespace = $3
+		AND s.tenant_id IS NULL AND s.user_id IS NULL AND s.scope = '' AND s.variant = ''
+		AND s.approved_value IS NOT NULL AND `+activeSQL("s", "NOW()")+`
+		ORDER BY s.key_path, s.`+latestEffective+`
+	`, sourceLang, targetLang, o.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e TranslatorEntry
+		var hasContext bool
+		m := KeyMetadata{Namespace: o.namespace}
+		if err = rows.Scan(&e.KeyPath, &e.Source, &e.Target, &hasContext, &m.Description, &m.Notes, &m.Screenshot, &m.MaxLength, &m.Tags); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		e.SourceHash = SourceHash(e.Source)
+		if hasContext {
+			m.KeyPath = e.KeyPath
+			e.Context = &m
+			e.TooLong = e.Target != nil && !m.Fits(*e.Target)
+		}
+		export.Entries = append(export.Entries, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("row iteration error: %w", rows.Err())
+	}
+	return export, nil
+}
@@ -0,0 +1,14 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKeyMetadata_Fits(t *testing.T) {
+	assert.True(t, KeyMetadata{}.Fits("no limit at all"))
+	assert.True(t, KeyMetadata{MaxLength: 5}.Fits("Hello"))
+	assert.False(t, KeyMetadata{MaxLength: 5}.Fits("Hello!"))
+	// Length is counted in characters, not bytes
+	assert.True(t, KeyMetadata{MaxLength: 5}.Fits("مرحبا"))
+}
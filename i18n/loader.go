@@ -0,0 +1,146 @@
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Common catalog layouts accepted by LoadDir.
+const (
+	// LayoutLangDir is the i18next layout, e.g. "locales/en/common.json".
+	LayoutLangDir = "{lang}/{namespace}.json"
+	// LayoutNamespaceLang puts both in the file name, e.g. "common.en.json".
+	LayoutNamespaceLang = "{namespace}.{lang}.json"
+	// LayoutLangFile is a single catalog per language, e.g. "en.json".
+	LayoutLangFile = "{lang}.json"
+)
+
+// langPattern matches language tags such as "en", "pt-BR" or "zh_Hant".
+const langPattern = `[A-Za-z]{2,3}(?:[-_][A-Za-z0-9]+)*`
+
+// pathPattern is a compiled catalog layout such as "{lang}/{namespace}.json".
+type pathPattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
+// compilePathPattern turns a layout into an anchored regular expression.
+// The layout must contain {lang} and may contain {namespace}; everything else
+// is matched literally against the slash-separated path.
+func compilePathPattern(pattern string) (*pathPattern, error) {
+	if !strings.Contains(pattern, "{lang}") {
+		return nil, fmt.Errorf("path pattern %q has no {lang} placeholder", pattern)
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	rest := pattern
+	for rest != "" {
+		i := strings.IndexByte(rest, '{')
+		if i < 0 {
+			sb.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		sb.WriteString(regexp.QuoteMeta(rest[:i]))
+		rest = rest[i:]
+		switch {
+		case strings.HasPrefix(rest, "{lang}"):
+			sb.WriteString("(?P<lang>" + langPattern + ")")
+			rest = rest[len("{lang}"):]
+		case strings.HasPrefix(rest, "{namespace}"):
+			sb.WriteString(`(?P<namespace>[^/]+)`)
+			rest = rest[len("{namespace}"):]
+		default:
+			return nil, fmt.Errorf("path pattern %q has an unknown placeholder at %q", pattern, rest)
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("path pattern %q: %w", pattern, err)
+	}
+	return &pathPattern{source: pattern, re: re}, nil
+}
+
+// match reports the language and namespace encoded in path.
+func (p *pathPattern) match(path string) (lang, namespace string, ok bool) {
+	m := p.re.FindStringSubmatch(path)
+	if m == nil {
+		return "", "", false
+	}
+	for i, name := range p.re.SubexpNames() {
+		switch name {
+		case "lang":
+			lang = m[i]
+		case "namespace":
+			namespace = m[i]
+		}
+	}
+	return lang, namespace, true
+}
+
+// LoadDir walks fsys and loads every JSON file whose path matches one of the
+// patterns, inferring language and namespace from the path. Files matching
+// no pattern are ignored. Each translation records the namespace inferred
+// from its file, so "en/checkout.json" yields rows in the "checkout" namespace.
+// Use os.DirFS to load from a directory on disk.
+func LoadDir(fsys fs.FS, patterns ...string) ([]Translation, error) {
+	if len(patterns) == 0 {
+		patterns = []string{LayoutLangDir}
+	}
+	compiled := make([]*pathPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := compilePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, p)
+	}
+
+	var translations []Translation
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		for _, p := range compiled {
+			lang, namespace, ok := p.match(path)
+			if !ok {
+				continue
+			}
+			flatMap, err := LoadAndFlattenFS(fsys, path)
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", path, err)
+			}
+			translations = append(translations, catalogTranslations(flatMap, lang, namespace)...)
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// catalogTranslations converts a flattened catalog into global translations,
+// sorted by key so repeated loads produce the same order.
+func catalogTranslations(flatMap map[string]string, lang, namespace string) []Translation {
+	keys := make([]string, 0, len(flatMap))
+	for k := range flatMap {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	translations := make([]Translation, 0, len(keys))
+	for _, k := range keys {
+		translations = append(translations, Translation{
+			Namespace: namespace,
+			Lang:      lang,
+			KeyPath:   k,
+			Value:     flatMap[k],
+		})
+	}
+	return translations
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPathPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		path      string
+		lang      string
+		namespace string
+		ok        bool
+	}{
+		{LayoutLangDir, "en/common.json", "en", "common", true},
+		{LayoutLangDir, "pt-BR/checkout.json", "pt-BR", "checkout", true},
+		{LayoutLangDir, "en/admin/users.json", "", "", false},
+		{LayoutNamespaceLang, "common.en.json", "en", "common", true},
+		{LayoutNamespaceLang, "admin.users.es.json", "es", "admin.users", true},
+		{LayoutLangFile, "ar.json", "ar", "", true},
+		{LayoutLangFile, "README.md", "", "", false},
+		{"locales/{lang}/{namespace}.json", "locales/fr/common.json", "fr", "common", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			p, err := compilePathPattern(tt.pattern)
+			assert.NoError(t, err)
+			lang, namespace, ok := p.match(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.lang, lang)
+			assert.Equal(t, tt.namespace, namespace)
+		})
+	}
+}
+
+func TestCompilePathPattern_Invalid(t *testing.T) {
+	_, err := compilePathPattern("{namespace}.json")
+	assert.Error(t, err)
+
+	_, err = compilePathPattern("{lang}/{bundle}.json")
+	assert.Error(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en/common.json":   {Data: []byte(`{"topbar": {"profile": "Profile", "logout": "Log out"}}`)},
+		"es/common.json":   {Data: []byte(`{"topbar": {"profile": "Perfil"}}`)},
+		"checkout.en.json": {Data: []byte(`{"pay": "Pay now"}`)},
+		"README.md":        {Data: []byte(`# locales`)},
+	}
+
+	translations, err := LoadDir(fsys, LayoutLangDir, LayoutNamespaceLang)
+	assert.NoError(t, err)
+	assert.Equal(t, []Translation{
+		{Namespace: "checkout", Lang: "en", KeyPath: "pay", Value: "Pay now"},
+		{Namespace: "common", Lang: "en", KeyPath: "topbar.logout", Value: "Log out"},
+		{Namespace: "common", Lang: "en", KeyPath: "topbar.profile", Value: "Profile"},
+		{Namespace: "common", Lang: "es", KeyPath: "topbar.profile", Value: "Perfil"},
+	}, translations)
+}
+
+func TestLoadDir_InvalidJSON(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en/common.json": {Data: []byte(`{invalid json}`)},
+	}
+
+	_, err := LoadDir(fsys)
+	assert.ErrorContains(t, err, "en/common.json")
+}
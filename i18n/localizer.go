@@ -0,0 +1,190 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"sync"
+	"time"
+)
+
+// Localizer formats translations for one user and set of lookup options
+// (tenant, scopes, namespace). It loads each language once with
+// ExportToFlatJSON and caches the values together with their parsed
+// messages, so formatting a key parses its value only the first time.
+//
+// Keys the database does not have are served from the fallback catalog, if
+// any. When the database cannot be read at all the fallback answers alone and
+// the language is loaded again on the next call. A language with scheduled
+// translations (see Translation.EffectiveFrom) is loaded again on the first
+// call after its next scheduled change. A Localizer is safe for concurrent
+// use; its database loads are serialized, as a *pgx.Conn requires.
+type Localizer struct {
+	conn     *pgx.Conn
+	fallback *Catalog
+	userID   *string
+	opts     []LookupOption
+	o        lookupOptions // opts applied
+
+	mu      sync.Mutex
+	bundles map[string]*bundle
+}
+
+// bundle is the cached content of one language.
+type bundle struct {
+	values   map[string]string
+	messages map[string]*Message
+	expires  time.Time // next scheduled change; zero if none
+}
+
+// current reports whether b still holds the values in effect at now.
+func (b *bundle) current(now time.Time) bool {
+	return b.expires.IsZero() || now.Before(b.expires)
+}
+
+// NewLocalizer returns a Localizer reading from conn, falling back to
+// fallback. Either may be nil: without conn the Localizer serves the catalog
+// only.
+func NewLocalizer(conn *pgx.Conn, fallback *Catalog, userID *string, opts ...LookupOption) *Localizer {
+	return &Localizer{
+		conn:     conn,
+		fallback: fallback,
+		userID:   userID,
+		opts:     opts,
+		o:        newLookupOptions(opts),
+		bundles:  map[string]*bundle{},
+	}
+}
+
+// Lookup returns the raw value of keyPath in lang. It returns an error
+// wrapping pgx.ErrNoRows if neither the database nor the fallback has it.
+func (l *Localizer) Lookup(ctx context.Context, keyPath, lang string) (string, error) {
+	l.mu.Lock()
+	b := l.bundle(ctx, lang)
+	value, ok := b.values[keyPath]
+	l.mu.Unlock()
+
+	if !ok {
+		return "", l.notFound(ctx, keyPath, lang)
+	}
+	l.o.reportUsage(ctx, keyPath, lang)
+	return value, nil
+}
+
+// Format renders the value of keyPath in lang as an ICU MessageFormat
+// message with args; see Message.Format. If keyPath itself is not stored but
+// plural variants suffixed with a category are (keyPath_one, keyPath_other,
+// ...), the variant is picked by the "count" argument: keyPath_zero when it
+// is 0 and that key exists, else the category of count in lang, else
+// keyPath_other. It returns an error wrapping pgx.ErrNoRows if the key is
+// missing, or a *SyntaxError if its value is not a valid message.
+func (l *Localizer) Format(ctx context.Context, keyPath, lang string, args map[string]any) (string, error) {
+	l.mu.Lock()
+	b := l.bundle(ctx, lang)
+	keyPath = b.pluralKey(keyPath, lang, args)
+	m, ok := b.messages[keyPath]
+	if !ok {
+		value, found := b.values[keyPath]
+		if !found {
+			l.mu.Unlock()
+			return "", l.notFound(ctx, keyPath, lang)
+		}
+		var err error
+		if m, err = ParseMessage(value); err != nil {
+			l.mu.Unlock()
+			return "", fmt.Errorf("failed to parse %q for %s: %w", keyPath, lang, err)
+		}
+		b.messages[keyPath] = m
+	}
+	l.mu.Unlock()
+
+	l.o.reportUsage(ctx, keyPath, lang)
+	return m.Format(lang, args), nil
+}
+
+// notFound reports keyPath to the missing-key handler, if any, and returns
+// the error for it.
+func (l *Localizer) notFound(ctx context.Context, keyPath, lang string) error {
+	l.o.reportMissing(ctx, keyPath, lang)
+	return fmt.Errorf("translation %q not found for %s: %w", keyPath, lang, pgx.ErrNoRows)
+}
+
+// Invalidate drops the cached languages, or every language if none are
+// given, so the next call reloads them from the database.
+func (l *Localizer) Invalidate(langs ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(langs) == 0 {
+		clear(l.bundles)
+		return
+	}
+	for _, lang := range langs {
+		delete(l.bundles, lang)
+	}
+}
+
+// pluralKey returns the suffixed variant of keyPath for the "count"
+// argument, or keyPath if it is stored as is or there is no count.
+func (b *bundle) pluralKey(keyPath, lang string, args map[string]any) string {
+	if _, ok := b.values[keyPath]; ok {
+		return keyPath
+	}
+	v, ok := args["count"]
+	if !ok {
+		return keyPath
+	}
+	count, ok := toNumber(v)
+	if !ok {
+		return keyPath
+	}
+	candidates := []string{keyPath + "_" + pluralCategory(lang, count, visibleFractionDigits(v, count), false), keyPath + "_other"}
+	if count == 0 {
+		candidates = append([]string{keyPath + "_zero"}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if _, ok := b.values[candidate]; ok {
+			return candidate
+		}
+	}
+	return keyPath
+}
+
+// bundle returns the cached bundle of lang, loading it if needed. l.mu must be held.
+func (l *Localizer) bundle(ctx context.Context, lang string) *bundle {
+	if b, ok := l.bundles[lang]; ok && b.current(time.Now()) {
+		return b
+	}
+
+	b := &bundle{values: map[string]string{}, messages: map[string]*Message{}}
+	if l.fallback != nil {
+		namespace := l.o.namespace
+		for _, t := range l.fallback.Translations() {
+			if t.Namespace == namespace && t.Lang == lang {
+				b.values[t.KeyPath] = t.Value
+			}
+		}
+	}
+	if l.conn == nil {
+		l.bundles[lang] = b
+		return b
+	}
+
+	exported, err := ExportToFlatJSON(ctx, l.conn, lang, l.userID, l.opts...)
+	if err != nil {
+		// Serve the fallback for now and retry the database next time.
+		return b
+	}
+	for key, entry := range exported {
+		b.values[key] = entry["value"]
+	}
+	next, err := NextScheduledChange(ctx, l.conn, lang, l.userID, l.opts...)
+	if err != nil {
+		return b
+	}
+	if next != nil && l.o.at == nil {
+		b.expires = *next
+	}
+	l.bundles[lang] = b
+	return b
+}
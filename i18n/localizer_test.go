@@ -0,0 +1,47 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLocalizer_Catalog(t *testing.T) {
+	catalog := NewCatalog([]Translation{
+		{Namespace: "inbox", Lang: "en", KeyPath: "unread", Value: "{n, plural, =0 {No messages} one {# message} other {# messages}}"},
+		{Namespace: "inbox", Lang: "de", KeyPath: "unread", Value: "{n, plural, one {# Nachricht} other {# Nachrichten}}"},
+		{Namespace: "inbox", Lang: "en", KeyPath: "broken", Value: "{n, plural, one {#}"},
+		{Lang: "en", KeyPath: "unread", Value: "Other namespace"},
+	})
+	l := NewLocalizer(nil, catalog, nil, WithNamespace("inbox"))
+	ctx := context.Background()
+
+	got, err := l.Format(ctx, "unread", "en", map[string]any{"n": 0})
+	assert.NoError(t, err)
+	assert.Equal(t, "No messages", got)
+
+	got, err = l.Format(ctx, "unread", "de", map[string]any{"n": 2000})
+	assert.NoError(t, err)
+	assert.Equal(t, "2.000 Nachrichten", got)
+
+	value, err := l.Lookup(ctx, "unread", "en")
+	assert.NoError(t, err)
+	assert.Equal(t, "{n, plural, =0 {No messages} one {# message} other {# messages}}", value)
+
+	_, err = l.Format(ctx, "missing", "en", nil)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = l.Format(ctx, "broken", "en", map[string]any{"n": 1})
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	// Parsed messages are cached until the language is invalidated
+	assert.Len(t, l.bundles["en"].messages, 1)
+	l.Invalidate("en")
+	assert.NotContains(t, l.bundles, "en")
+	assert.Contains(t, l.bundles, "de")
+	l.Invalidate()
+	assert.Empty(t, l.bundles)
+}
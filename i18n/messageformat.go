@@ -0,0 +1,419 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Message is a parsed ICU MessageFormat pattern such as
+//
+//	{count, plural, =0 {No items} one {# item} other {# items}} in {folder}
+//
+// It supports simple arguments, number, date and time arguments with a style
+// or a "::" skeleton, plural, selectordinal and select. Parse a value once
+// with ParseMessage and format it many times with Format.
+type Message struct {
+	parts []messagePart
+}
+
+// messagePart is one of textPart, argPart, pluralPart, selectPart or poundPart.
+type messagePart interface{}
+
+type textPart string
+
+// poundPart is "#" inside a plural, the plural argument minus the offset.
+type poundPart struct{}
+
+// argPart is "{name}", "{name, number[, style]}", "{name, date[, style]}" or
+// "{name, time[, style]}".
+type argPart struct {
+	name   string
+	kind   string // "", "number", "date" or "time"
+	number numberStyle
+	layout string // Go time layout for date and time arguments
+}
+
+type pluralPart struct {
+	name    string
+	ordinal bool // selectordinal
+	offset  float64
+	cases   []pluralCase
+}
+
+type pluralCase struct {
+	selector string   // "=N" or a plural category
+	exact    *float64 // N of "=N"
+	message  *Message
+}
+
+type selectPart struct {
+	name  string
+	cases []selectCase
+}
+
+type selectCase struct {
+	selector string
+	message  *Message
+}
+
+// SyntaxError reports an invalid MessageFormat pattern.
+type SyntaxError struct {
+	Offset int // byte offset in the pattern
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("message syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+// pluralCategories are the CLDR plural categories, the keywords a plural or
+// selectordinal case may use besides "=N".
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// ParseMessage parses an ICU MessageFormat pattern. Apostrophes quote
+// special characters as in ICU: '{' is a literal brace, two apostrophes in a
+// row are a single one, and any other apostrophe is literal text.
+func ParseMessage(pattern string) (*Message, error) {
+	p := &messageParser{src: pattern}
+	m, err := p.message(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unmatched }")
+	}
+	return m, nil
+}
+
+type messageParser struct {
+	src string
+	pos int
+}
+
+func (p *messageParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// message parses text and arguments up to an unmatched "}" or the end of the
+// pattern. inPlural makes "#" a poundPart.
+func (p *messageParser) message(inPlural bool) (*Message, error) {
+	m := &Message{}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			m.parts = append(m.parts, textPart(text.String()))
+			text.Reset()
+		}
+	}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\'':
+			p.quoted(&text, inPlural)
+		case c == '{':
+			flush()
+			part, err := p.argument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			m.parts = append(m.parts, part)
+		case c == '}':
+			flush()
+			return m, nil
+		case c == '#' && inPlural:
+			flush()
+			m.parts = append(m.parts, poundPart{})
+			p.pos++
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+	flush()
+	return m, nil
+}
+
+// quoted handles an apostrophe at p.pos, writing the literal text it stands for.
+func (p *messageParser) quoted(text *strings.Builder, inPlural bool) {
+	p.pos++
+	if p.pos >= len(p.src) {
+		text.WriteByte('\'')
+		return
+	}
+	switch c := p.src[p.pos]; {
+	case c == '\'':
+		text.WriteByte('\'')
+		p.pos++
+		return
+	case c == '{' || c == '}' || c == '|' || (c == '#' && inPlural):
+	default:
+		text.WriteByte('\'')
+		return
+	}
+	// Quoted literal: runs up to the next single apostrophe or the end.
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		if c != '\'' {
+			text.WriteByte(c)
+			continue
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+			text.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func (p *messageParser) skipSpace() {
+	for p.pos < len(p.src) {
+		r := rune(p.src[p.pos])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos++
+	}
+}
+
+// word reads a name, type, keyword or selector: everything up to white
+// space or a syntax character.
+func (p *messageParser) word() string {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune("{},# \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *messageParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return p.errorf("expected %q, found end of message", c)
+	}
+	if p.src[p.pos] != c {
+		return p.errorf("expected %q, found %q", c, p.src[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// argument parses "{...}" starting at the opening brace.
+func (p *messageParser) argument(inPlural bool) (messagePart, error) {
+	p.pos++
+	p.skipSpace()
+	name := p.word()
+	if name == "" {
+		return nil, p.errorf("expected argument name")
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+		return argPart{name: name}, nil
+	}
+	if err := p.expect(','); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	typeOffset := p.pos
+	kind := p.word()
+	p.skipSpace()
+
+	switch kind {
+	case "plural", "selectordinal":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		return p.plural(name, kind == "selectordinal")
+	case "select":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		return p.selectArg(name, inPlural)
+	case "number", "date", "time":
+	default:
+		p.pos = typeOffset
+		return nil, p.errorf("unknown argument type %q", kind)
+	}
+
+	style := ""
+	if p.pos < len(p.src) && p.src[p.pos] == ',' {
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] != '}' {
+			if p.src[p.pos] == '{' {
+				return nil, p.errorf("unexpected { in %s style", kind)
+			}
+			p.pos++
+		}
+		style = strings.TrimSpace(p.src[start:p.pos])
+	}
+	styleOffset := p.pos
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+
+	arg := argPart{name: name, kind: kind}
+	var err error
+	if kind == "number" {
+		arg.number, err = parseNumberStyle(style)
+	} else {
+		arg.layout, err = dateLayout(kind, style)
+	}
+	if err != nil {
+		return nil, &SyntaxError{Offset: styleOffset, Msg: err.Error()}
+	}
+	return arg, nil
+}
+
+// plural parses the cases of a plural or selectordinal argument and its
+// closing brace.
+func (p *messageParser) plural(name string, ordinal bool) (messagePart, error) {
+	part := pluralPart{name: name, ordinal: ordinal}
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], "offset:") {
+		p.pos += len("offset:")
+		p.skipSpace()
+		word := p.word()
+		offset, err := strconv.ParseFloat(word, 64)
+		if err != nil || offset < 0 {
+			return nil, p.errorf("invalid plural offset %q", word)
+		}
+		part.offset = offset
+	}
+	seen := map[string]bool{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("expected \"}\", found end of message")
+		}
+		if p.src[p.pos] == '}' {
+			break
+		}
+		selectorOffset := p.pos
+		c := pluralCase{selector: p.word()}
+		if strings.HasPrefix(c.selector, "=") {
+			n, err := strconv.ParseFloat(c.selector[1:], 64)
+			if err != nil {
+				p.pos = selectorOffset
+				return nil, p.errorf("invalid plural selector %q", c.selector)
+			}
+			c.exact = &n
+		} else if !isPluralCategory(c.selector) {
+			p.pos = selectorOffset
+			return nil, p.errorf("invalid plural selector %q", c.selector)
+		}
+		if seen[c.selector] {
+			p.pos = selectorOffset
+			return nil, p.errorf("duplicate selector %q", c.selector)
+		}
+		seen[c.selector] = true
+		var err error
+		if c.message, err = p.caseMessage(true); err != nil {
+			return nil, err
+		}
+		part.cases = append(part.cases, c)
+	}
+	if !seen["other"] {
+		return nil, p.errorf("plural argument %q has no \"other\" case", name)
+	}
+	p.pos++
+	return part, nil
+}
+
+// selectArg parses the cases of a select argument and its closing brace.
+func (p *messageParser) selectArg(name string, inPlural bool) (messagePart, error) {
+	part := selectPart{name: name}
+	seen := map[string]bool{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("expected \"}\", found end of message")
+		}
+		if p.src[p.pos] == '}' {
+			break
+		}
+		selectorOffset := p.pos
+		c := selectCase{selector: p.word()}
+		if c.selector == "" {
+			return nil, p.errorf("expected select keyword")
+		}
+		if seen[c.selector] {
+			p.pos = selectorOffset
+			return nil, p.errorf("duplicate selector %q", c.selector)
+		}
+		seen[c.selector] = true
+		var err error
+		if c.message, err = p.caseMessage(inPlural); err != nil {
+			return nil, err
+		}
+		part.cases = append(part.cases, c)
+	}
+	if !seen["other"] {
+		return nil, p.errorf("select argument %q has no \"other\" case", name)
+	}
+	p.pos++
+	return part, nil
+}
+
+// caseMessage parses "{message}" of a plural or select case.
+func (p *messageParser) caseMessage(inPlural bool) (*Message, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	m, err := p.message(inPlural)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected \"}\", found end of message")
+	}
+	p.pos++
+	return m, nil
+}
+
+func isPluralCategory(s string) bool {
+	for _, c := range pluralCategories {
+		if c == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Arguments returns the names of the arguments the message uses, including
+// those of nested messages, in order of first appearance.
+func (m *Message) Arguments() []string {
+	var names []string
+	seen := map[string]bool{}
+	var walk func(*Message)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	walk = func(m *Message) {
+		for _, part := range m.parts {
+			switch part := part.(type) {
+			case argPart:
+				add(part.name)
+			case pluralPart:
+				add(part.name)
+				for _, c := range part.cases {
+					walk(c.message)
+				}
+			case selectPart:
+				add(part.name)
+				for _, c := range part.cases {
+					walk(c.message)
+				}
+			}
+		}
+	}
+	walk(m)
+	return names
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMessage_Format(t *testing.T) {
+	launch := time.Date(2025, time.March, 7, 14, 5, 9, 0, time.UTC)
+	tests := []struct {
+		name    string
+		pattern string
+		lang    string
+		args    map[string]any
+		want    string
+	}{
+		{"plain text", "Hello", "en", nil, "Hello"},
+		{"argument", "Hello {name}!", "en", map[string]any{"name": "Ana"}, "Hello Ana!"},
+		{"missing argument", "Hello {name}!", "en", nil, "Hello {name}!"},
+		{"number argument", "{count} items", "de", map[string]any{"count": 1234.5}, "1.234,5 items"},
+		{"quoted braces", "Use '{name}' or '{'", "en", map[string]any{"name": "x"}, "Use {name} or {"},
+		{"doubled apostrophe", "It''s {name}'s", "en", map[string]any{"name": "Ana"}, "It's Ana's"},
+		{"number style", "{n, number, integer}", "en", map[string]any{"n": 2.7}, "3"},
+		{"percent style", "{n, number, percent}", "en", map[string]any{"n": 0.25}, "25%"},
+		{"precision skeleton", "{n, number, ::.00}", "fr", map[string]any{"n": 1234}, "1 234,00"},
+		{"percent skeleton", "{n, number, ::percent}", "en", map[string]any{"n": 25}, "25%"},
+		{"percent scale skeleton", "{n, number, ::percent scale/100}", "en", map[string]any{"n": 0.25}, "25%"},
+		{"currency skeleton", "{n, number, ::currency/EUR}", "en", map[string]any{"n": 9.5}, "€ 9.50"},
+		{"sign skeleton", "{n, number, ::sign-always group-off}", "en", map[string]any{"n": 1500}, "+1500"},
+		{"date style", "{d, date, long}", "en", map[string]any{"d": launch}, "March 7, 2025"},
+		{"time style", "{d, time, short}", "en", map[string]any{"d": launch}, "2:05 PM"},
+		{"date skeleton", "{d, date, ::EEEyMMMd}", "en", map[string]any{"d": launch}, "Fri, Mar 7, 2025"},
+		{"numeric date skeleton", "{d, date, ::yMMdd}", "en", map[string]any{"d": launch}, "03/07/2025"},
+		{"time skeleton", "{d, time, ::Hms}", "en", map[string]any{"d": launch}, "14:05:09"},
+		{"date from millis", "{d, date, ::yMd}", "en", map[string]any{"d": launch.UnixMilli()}, "3/7/2025"},
+		{"plural exact", "{n, plural, =0 {No items} one {# item} other {# items}}", "en", map[string]any{"n": 0}, "No items"},
+		{"plural one", "{n, plural, =0 {No items} one {# item} other {# items}}", "en", map[string]any{"n": 1}, "1 item"},
+		{"plural other", "{n, plural, =0 {No items} one {# item} other {# items}}", "en", map[string]any{"n": 1200}, "1,200 items"},
+		{"plural offset",
+			"{n, plural, offset:1 =0 {Nobody} =1 {{host}} one {{host} and # other} other {{host} and # others}}", "en",
+			map[string]any{"n": 3, "host": "Ana"}, "Ana and 2 others"},
+		{"plural offset one", "{n, plural, offset:1 =1 {Just you} one {You and # other} other {You and # others}}", "en",
+			map[string]any{"n": 2}, "You and 1 other"},
+		{"selectordinal", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", "en", map[string]any{"n": 22}, "22nd"},
+		{"selectordinal teen", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", "en", map[string]any{"n": 13}, "13th"},
+		{"select", "{gender, select, female {She} male {He} other {They}} replied", "en", map[string]any{"gender": "female"}, "She replied"},
+		{"select other", "{gender, select, female {She} male {He} other {They}} replied", "en", map[string]any{"gender": "x"}, "They replied"},
+		{"nested",
+			"{gender, select, female {{n, plural, one {She has # file} other {She has # files}}} other {{n, plural, one {They have # file} other {They have # files}}}}",
+			"en", map[string]any{"gender": "female", "n": 2}, "She has 2 files"},
+		{"pound outside plural", "Issue #{id}", "en", map[string]any{"id": 7}, "Issue #7"},
+		{"quoted pound", "{n, plural, other {'#' is #}}", "en", map[string]any{"n": 4}, "# is 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMessage(tt.pattern)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, m.Format(tt.lang, tt.args))
+		})
+	}
+}
+
+func TestParseMessage_Errors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		offset  int
+	}{
+		{"Hello {name", 11},
+		{"Hello }", 6},
+		{"{}", 1},
+		{"{n, spellout}", 4},
+		{"{n, plural, one {#}}", 19},
+		{"{n, plural, some {#} other {#}}", 12},
+		{"{n, plural, one {#} one {#} other {#}}", 20},
+		{"{g, select, male {He}}", 21},
+		{"{n, number, #,##0}", 17},
+		{"{n, number, ::compact-short}", 27},
+		{"{d, date, ::yQQQ}", 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := ParseMessage(tt.pattern)
+			var syntaxErr *SyntaxError
+			if assert.True(t, errors.As(err, &syntaxErr), "error %v", err) {
+				assert.Equal(t, tt.offset, syntaxErr.Offset, syntaxErr.Msg)
+			}
+		})
+	}
+}
+
+func TestMessage_Arguments(t *testing.T) {
+	m, err := ParseMessage("{host} invited {n, plural, one {{guest}} other {# people}} to {event, select, other {{title}}}")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host", "n", "guest", "event", "title"}, m.Arguments())
+}
@@ -0,0 +1,258 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"sync"
+	"time"
+)
+
+// MissingKey is a lookup that found no value for its key.
+type MissingKey struct {
+	Namespace string
+	KeyPath   string
+	Lang      string
+	TenantID  *string // the tenant given with WithTenant, if any
+	Scope     string  // the most specific scope given with WithScopes, if any
+}
+
+// MissingKeyHandler is called by lookups that find no value; see
+// WithMissingKeyHandler. It runs on the caller's goroutine, so it should
+// return quickly.
+type MissingKeyHandler func(ctx context.Context, key MissingKey)
+
+// WithMissingKeyHandler makes GetTranslation, GetVariantTranslation and a
+// Localizer's Lookup and Format call h whenever a key has no value, before
+// returning the error wrapping pgx.ErrNoRows. Use a MissingKeyRecorder's
+// Record method to collect the keys real traffic asks for.
+func WithMissingKeyHandler(h MissingKeyHandler) LookupOption {
+	return func(o *lookupOptions) {
+		o.missingKey = h
+	}
+}
+
+// reportMissing calls the handler of o, if any, for keyPath in lang.
+func (o lookupOptions) reportMissing(ctx context.Context, keyPath, lang string) {
+	if o.missingKey == nil {
+		return
+	}
+	key := MissingKey{Namespace: o.namespace, KeyPath: keyPath, Lang: lang, TenantID: o.tenantID}
+	if len(o.scopes) > 0 {
+		key.Scope = o.scopes[0]
+	}
+	o.missingKey(ctx, key)
+}
+
+// missingKeyRow identifies a row of ui_missing_keys.
+type missingKeyRow struct {
+	tenantID  string
+	hasTenant bool
+	scope     string
+	namespace string
+	keyPath   string
+	lang      string
+}
+
+// missingCount aggregates the observations of one row between flushes.
+type missingCount struct {
+	count     int64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// MissingKeyRecorder collects missing keys in memory and writes them to
+// ui_missing_keys in batches, one row per namespace, key, language, tenant
+// and scope with the number of observations and when they were first and
+// last seen. Record is cheap and safe for concurrent use; call Flush
+// periodically or let Run do it. Give the recorder a connection of its own,
+// since a *pgx.Conn cannot be shared by concurrent callers.
+type MissingKeyRecorder struct {
+	conn *pgx.Conn
+
+	mu      sync.Mutex
+	pending map[missingKeyRow]*missingCount
+
+	flushMu sync.Mutex
+}
+
+// NewMissingKeyRecorder returns a recorder writing to conn.
+func NewMissingKeyRecorder(conn *pgx.Conn) *MissingKeyRecorder {
+	return &MissingKeyRecorder{conn: conn, pending: map[missingKeyRow]*missingCount{}}
+}
+
+// Record notes one lookup of a missing key. Its signature matches
+// MissingKeyHandler, so r.Record can be passed to WithMissingKeyHandler.
+func (r *MissingKeyRecorder) Record(_ context.Context, key MissingKey) {
+	r.add(key, time.Now())
+}
+
+func (r *MissingKeyRecorder) add(key MissingKey, seen time.Time) {
+	row := missingKeyRow{scope: key.Scope, namespace: key.Namespace, keyPath: key.KeyPath, lang: key.Lang}
+	if key.TenantID != nil {
+		row.tenantID, row.hasTenant = *key.TenantID, true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.merge(row, &missingCount{count: 1, firstSeen: seen, lastSeen: seen})
+}
+
+// merge adds c to the pending counts of row. r.mu must be held.
+func (r *MissingKeyRecorder) merge(row missingKeyRow, c *missingCount) {
+	p, ok := r.pending[row]
+	if !ok {
+		r.pending[row] = c
+		return
+	}
+	p.count += c.count
+	if c.firstSeen.Before(p.firstSeen) {
+		p.firstSeen = c.firstSeen
+	}
+	if c.lastSeen.After(p.lastSeen) {
+		p.lastSeen = c.lastSeen
+	}
+}
+
+// Flush writes the observations recorded since the last flush in one
+// transaction. If it fails they are kept for the next flush.
+func (r *MissingKeyRecorder) Flush(ctx context.Context) error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = map[missingKeyRow]*missingCount{}
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := r.write(ctx, pending); err != nil {
+		r.mu.Lock()
+		for row, c := range pending {
+			r.merge(row, c)
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (r *MissingKeyRecorder) write(ctx context.Context, pending map[missingKeyRow]*missingCount) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Rows of several tenants share the transaction, so each insert sets the
+	// tenant the row-level security policies check it against; "" is global.
+	batch := &pgx.Batch{}
+	for row, c := range pending {
+		var tenantID *string
+		if row.hasTenant {
+			tenantID = &row.tenantID
+		}
+		batch.Queue("SELECT set_config($1, $2, true)", TenantSetting, row.tenantID)
+		batch.Queue(`
+			INSERT INTO ui_missing_keys (tenant_id, scope, namespace, key_path, lang, count, first_seen, last_seen)
+			VALUES ($1::uuid, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (tenant_id, scope, namespace, key_path, lang) DO UPDATE SET
+				count = ui_missing_keys.count + EXCLUDED.count,
+				first_seen = LEAST(ui_missing_keys.first_seen, EXCLUDED.first_seen),
+				last_seen = GREATEST(ui_missing_keys.last_seen, EXCLUDED.last_seen)
+		`, tenantID, row.scope, row.namespace, row.keyPath, row.lang, c.count, c.firstSeen, c.lastSeen)
+	}
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record missing keys: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// Run flushes the recorder every interval until ctx is done, then flushes
+// one last time within a second. It returns the error of that last flush;
+// errors of periodic flushes are retried at the next tick.
+func (r *MissingKeyRecorder) Run(ctx context.Context, interval time.Duration) error {
+	return runFlushes(ctx, interval, r.Flush)
+}
+
+// runFlushes calls flush every interval until ctx is done, then once more
+// with a second to complete, and returns the error of that last call.
+func runFlushes(ctx context.Context, interval time.Duration, flush func(context.Context) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+			defer cancel()
+			return flush(flushCtx)
+		}
+	}
+}
+
+// MissingKeyStat is a row of ui_missing_keys.
+type MissingKeyStat struct {
+	Namespace string    `json:"namespace"`
+	KeyPath   string    `json:"key_path"`
+	Lang      string    `json:"lang"`
+	TenantID  *string   `json:"tenant_id,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// ListMissingKeys lists the keys of the namespace (the default one without
+// WithNamespace) recorded as missing and still without a value the missed
+// lookup would now be served: an approved control value in effect, stored
+// globally, for the tenant or for the scope of the miss. Overrides of other
+// tenants, scopes or users do not count. Most requested keys come first. With WithTenant only keys missed
+// without a tenant or by that tenant are listed.
+func ListMissingKeys(ctx context.Context, conn *pgx.Conn, opts ...LookupOption) ([]MissingKeyStat, error) {
+	o := newLookupOptions(opts)
+	query := `
+		SELECT m.tenant_id::text, m.scope, m.key_path, m.lang, m.count, m.first_seen, m.last_seen
+		FROM ui_missing_keys m
+		WHERE m.namespace = $1 AND ($2::uuid IS NULL OR m.tenant_id IS NULL OR m.tenant_id = $2::uuid)
+		AND NOT EXISTS (
+			SELECT 1 FROM ui_translations t
+			WHERE t.namespace = m.namespace AND t.key_path = m.key_path AND t.lang = m.lang
+			AND t.user_id IS NULL AND (t.tenant_id IS NULL OR t.tenant_id = m.tenant_id) AND t.scope IN ('', m.scope)
+			AND t.approved_value IS NOT NULL AND t.variant = '' AND ` + activeSQL("t", "NOW()") + `
+		)
+		ORDER BY m.count DESC, m.key_path, m.lang
+	`
+
+	var stats []MissingKeyStat
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, o.namespace, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			s := MissingKeyStat{Namespace: o.namespace}
+			if err = rows.Scan(&s.TenantID, &s.Scope, &s.KeyPath, &s.Lang, &s.Count, &s.FirstSeen, &s.LastSeen); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			stats = append(stats, s)
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithMissingKeyHandler(t *testing.T) {
+	var got []MissingKey
+	handler := func(_ context.Context, key MissingKey) { got = append(got, key) }
+	tenant := "tenant"
+
+	o := newLookupOptions([]LookupOption{WithNamespace("checkout"), WithTenant(tenant), WithScopes("team:7", "workspace:3"), WithMissingKeyHandler(handler)})
+	o.reportMissing(context.Background(), "cart.title", "de")
+	newLookupOptions(nil).reportMissing(context.Background(), "ignored", "de")
+	assert.Equal(t, []MissingKey{{Namespace: "checkout", KeyPath: "cart.title", Lang: "de", TenantID: &tenant, Scope: "team:7"}}, got)
+
+	// The Localizer reports keys neither the database nor the fallback has
+	got = nil
+	catalog := NewCatalog([]Translation{{Lang: "en", KeyPath: "known", Value: "Known"}})
+	l := NewLocalizer(nil, catalog, nil, WithMissingKeyHandler(handler))
+	_, err := l.Lookup(context.Background(), "known", "en")
+	assert.NoError(t, err)
+	_, err = l.Lookup(context.Background(), "unknown", "en")
+	assert.Error(t, err)
+	_, err = l.Format(context.Background(), "files", "en", map[string]any{"count": 2})
+	assert.Error(t, err)
+	assert.Equal(t, []MissingKey{{KeyPath: "unknown", Lang: "en"}, {KeyPath: "files", Lang: "en"}}, got)
+}
+
+func TestMissingKeyRecorder_Aggregates(t *testing.T) {
+	r := NewMissingKeyRecorder(nil)
+	tenant := "tenant"
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	r.add(MissingKey{KeyPath: "cart.title", Lang: "de"}, first.Add(time.Minute))
+	r.add(MissingKey{KeyPath: "cart.title", Lang: "de"}, first)
+	r.add(MissingKey{KeyPath: "cart.title", Lang: "de"}, first.Add(time.Hour))
+	r.add(MissingKey{KeyPath: "cart.title", Lang: "de", TenantID: &tenant}, first)
+	r.add(MissingKey{KeyPath: "cart.title", Lang: "fr"}, first)
+
+	assert.Len(t, r.pending, 3)
+	c := r.pending[missingKeyRow{keyPath: "cart.title", lang: "de"}]
+	if assert.NotNil(t, c) {
+		assert.Equal(t, int64(3), c.count)
+		assert.Equal(t, first, c.firstSeen)
+		assert.Equal(t, first.Add(time.Hour), c.lastSeen)
+	}
+
+	// Flushing nothing needs no connection
+	empty := NewMissingKeyRecorder(nil)
+	assert.NoError(t, empty.Flush(context.Background()))
+}
@@ -1,10 +1,57 @@
 package i18n
 
+import "time"
+
 // Translation represents a single translation entry.
 type Translation struct {
-	UserID  *string // nil = global/default translation
-	Lang    string
-	KeyPath string
+	TenantID   *string // nil = not tied to a tenant
+	UserID     *string // nil = global/default translation
+	Scope      string  // override scope within the tenant, e.g. "team:7"; "" = none
+	Namespace  string  // "" = default namespace
+	Lang       string
+	KeyPath    string
+	Variant    string // experiment variant, see Experiment; ControlVariant ("") = the ordinary value
+	Value      string
+	Attributes Attributes // per-string variants such as AttrTooltip
+	SourceHash string     // SourceHash of the source-language value translated from; "" = the current one WithSourceLang, else unknown
+
+	// EffectiveFrom and EffectiveUntil bound when the value is served; nil
+	// leaves that side open. EffectiveFrom is part of the row's key, so a
+	// value scheduled for a launch is stored beside the current one and wins
+	// over it once in effect.
+	EffectiveFrom  *time.Time
+	EffectiveUntil *time.Time
+
+	// Deprecated: set Attributes[AttrTooltip] instead. ToolTip is still
+	// stored as the tooltip attribute when Attributes has none.
 	ToolTip string
-	Value   string
+}
+
+// rowKey identifies a row of ui_translations by its unique key.
+type rowKey struct {
+	tenantID  string
+	userID    string
+	hasTenant bool
+	hasUser   bool
+	from      int64 // EffectiveFrom in microseconds, as Postgres stores it
+	hasFrom   bool
+	scope     string
+	namespace string
+	lang      string
+	keyPath   string
+	variant   string
+}
+
+func (t Translation) rowKey() rowKey {
+	k := rowKey{scope: t.Scope, namespace: t.Namespace, lang: t.Lang, keyPath: t.KeyPath, variant: t.Variant}
+	if t.TenantID != nil {
+		k.tenantID, k.hasTenant = *t.TenantID, true
+	}
+	if t.UserID != nil {
+		k.userID, k.hasUser = *t.UserID, true
+	}
+	if t.EffectiveFrom != nil {
+		k.from, k.hasFrom = t.EffectiveFrom.UnixMicro(), true
+	}
+	return k
 }
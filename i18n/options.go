@@ -0,0 +1,97 @@
+package i18n
+
+import "time"
+
+// LookupOption narrows the rows considered by GetTranslation and ExportToFlatJSON.
+// Writes such as UpsertTranslations accept WithSourceLang.
+type LookupOption func(*lookupOptions)
+
+type lookupOptions struct {
+	namespace string
+	tenantID  *string
+	scopes    []string
+
+	exportMode ExportMode
+	drafts     bool
+	at         *time.Time
+	variant    string
+	missingKey MissingKeyHandler
+	keyUsage   KeyUsageHandler
+
+	sourceLang string
+}
+
+func newLookupOptions(opts []LookupOption) lookupOptions {
+	var o lookupOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithNamespace restricts a lookup or export to namespace, so each frontend
+// bundle can be loaded on its own. The default namespace is "".
+func WithNamespace(namespace string) LookupOption {
+	return func(o *lookupOptions) {
+		o.namespace = namespace
+	}
+}
+
+// WithTenant makes a lookup or export consider the tenant's overrides, which
+// take precedence over global translations but not over user overrides.
+func WithTenant(tenantID string) LookupOption {
+	return func(o *lookupOptions) {
+		o.tenantID = &tenantID
+	}
+}
+
+// WithScopes makes a lookup or export walk the given scope identifiers, most
+// specific first, between the user and the tenant. For an org → workspace →
+// team hierarchy pass WithTenant(org) and WithScopes(team, workspace).
+func WithScopes(scopes ...string) LookupOption {
+	return func(o *lookupOptions) {
+		o.scopes = append(o.scopes, scopes...)
+	}
+}
+
+// WithExportMode selects which rows ExportToFlatJSON returns. It has no
+// effect on GetTranslation.
+func WithExportMode(mode ExportMode) LookupOption {
+	return func(o *lookupOptions) {
+		o.exportMode = mode
+	}
+}
+
+// WithDrafts makes a lookup or export serve the latest value of each row
+// whatever its review status, e.g. to preview changes awaiting review. By
+// default only approved values are served; see SubmitForReview.
+func WithDrafts() LookupOption {
+	return func(o *lookupOptions) {
+		o.drafts = true
+	}
+}
+
+// valueColumn returns the column lookups read values from.
+func (o lookupOptions) valueColumn() string {
+	if o.drafts {
+		return "value"
+	}
+	return "approved_value"
+}
+
+// attributesColumn returns the SQL expression exports read attributes from,
+// matching valueColumn: with WithDrafts, those pending review if any.
+func (o lookupOptions) attributesColumn() string {
+	if o.drafts {
+		return "COALESCE(pending_attributes, attributes)"
+	}
+	return "attributes"
+}
+
+// WithTime resolves a lookup or export against the translations in effect at
+// t instead of now, e.g. to preview copy scheduled for a launch.
+func WithTime(t time.Time) LookupOption {
+	return func(o *lookupOptions) {
+		o.at = &t
+	}
+}
@@ -0,0 +1,197 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+// OverrideScope identifies where a set of rows is stored: a tenant,
+// optionally narrowed to a scope or a user within it. The zero value is the
+// global scope.
+type OverrideScope struct {
+	TenantID *string
+	UserID   *string
+	Scope    string
+}
+
+// GlobalScope is the scope of the global translations.
+var GlobalScope = OverrideScope{}
+
+// condition returns a SQL condition matching rows of the table aliased as
+// alias that are stored exactly in s.
+func (s OverrideScope) condition(alias string, args *queryArgs) string {
+	return fmt.Sprintf("%[1]s.tenant_id IS NOT DISTINCT FROM %[2]s::uuid AND %[1]s.user_id IS NOT DISTINCT FROM %[3]s::uuid AND %[1]s.scope = %[4]s",
+		alias, args.add(s.TenantID), args.add(s.UserID), args.add(s.Scope))
+}
+
+// OverrideFilter selects the rows CopyOverrides copies. Empty fields match
+// everything.
+type OverrideFilter struct {
+	Namespaces []string
+	Langs      []string
+	KeyPrefix  string
+	Keys       []string
+}
+
+// condition returns a SQL condition matching rows of the table aliased as
+// alias that pass the filter.
+func (f OverrideFilter) condition(alias string, args *queryArgs) string {
+	conditions := []string{"TRUE"}
+	if len(f.Namespaces) > 0 {
+		conditions = append(conditions, alias+".namespace = ANY("+args.add(f.Namespaces)+")")
+	}
+	if len(f.Langs) > 0 {
+		conditions = append(conditions, alias+".lang = ANY("+args.add(f.Langs)+")")
+	}
+	if f.KeyPrefix != "" {
+		conditions = append(conditions, "starts_with("+alias+".key_path, "+args.add(f.KeyPrefix)+")")
+	}
+	if len(f.Keys) > 0 {
+		conditions = append(conditions, alias+".key_path = ANY("+args.add(f.Keys)+")")
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// CopyOverrides copies the rows stored in from that match filter into to,
+// overwriting rows to already has for the same namespace, key and language.
+// Rows whose value would not change are skipped. The copy runs in one
+// transaction and records an audit entry per changed row, attributed to
+// actor. It returns the number of rows written. With row-level security
+// enabled, copies between tenants need a role that bypasses it.
+func CopyOverrides(ctx context.Context, conn *pgx.Conn, from, to OverrideScope, filter OverrideFilter, actor *string) (int, error) {
+	return copyScope(ctx, conn, "copy", from, to, filter, actor)
+}
+
+// PromoteToGlobal makes the values stored in scope for keys the global
+// default, in every namespace and language the scope overrides them. The
+// overrides themselves are kept. The promotion runs in one transaction and
+// records an audit entry per changed row, attributed to actor. With
+// row-level security enabled it needs a role that bypasses it.
+func PromoteToGlobal(ctx context.Context, conn *pgx.Conn, scope OverrideScope, keys []string, actor *string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return copyScope(ctx, conn, "promote", scope, GlobalScope, OverrideFilter{Keys: keys}, actor)
+}
+
+// auditColumns are the ui_translation_audit columns written by recordAudit.
+var auditColumns = []string{
+	"action", "source_tenant_id", "source_user_id", "source_scope",
+	"tenant_id", "user_id", "scope", "namespace", "key_path", "lang",
+	"old_value", "new_value", "actor", "created_at",
+}
+
+// auditEntry is one changed row in ui_translation_audit.
+type auditEntry struct {
+	action   string
+	source   OverrideScope
+	target   Translation
+	oldValue *string
+	actor    *string
+}
+
+// recordAudit writes audit entries within tx.
+func recordAudit(ctx context.Context, tx querier, entries []auditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([][]any, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, []any{
+			e.action, e.source.TenantID, e.source.UserID, e.source.Scope,
+			e.target.TenantID, e.target.UserID, e.target.Scope, e.target.Namespace, e.target.KeyPath, e.target.Lang,
+			e.oldValue, e.target.Value, e.actor, now,
+		})
+	}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"ui_translation_audit"}, auditColumns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return fmt.Errorf("failed to record audit entries: %w", err)
+	}
+	return nil
+}
+
+// copyScope copies the rows of from matching filter into to and audits the change.
+// Rows to already has are matched with IS NOT DISTINCT FROM and updated in
+// place rather than left to the upsert, so a NULL tenant or user in to
+// never yields a duplicate, whichever unique key the table was given.
+func copyScope(ctx context.Context, conn *pgx.Conn, action string, from, to OverrideScope, filter OverrideFilter, actor *string) (int, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var args queryArgs
+	query := `
+		SELECT s.namespace, s.key_path, s.lang, s.variant, s.approved_value, s.attributes, COALESCE(s.source_hash, ''),
+			s.effective_from, s.effective_until, t.id, t.approved_value
+		FROM ui_translations s
+		LEFT JOIN ui_translations t
+			ON t.namespace = s.namespace AND t.key_path = s.key_path AND t.lang = s.lang
+			AND t.variant = s.variant AND t.effective_from IS NOT DISTINCT FROM s.effective_from
+			AND ` + to.condition("t", &args) + `
+		WHERE ` + from.condition("s", &args) + ` AND ` + filter.condition("s", &args) + `
+		AND s.approved_value IS NOT NULL AND t.approved_value IS DISTINCT FROM s.approved_value
+		ORDER BY s.namespace, s.key_path, s.lang
+	`
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("query failed: %w", err)
+	}
+	var inserts []Translation
+	var entries []auditEntry
+	updates := &pgx.Batch{}
+	for rows.Next() {
+		tr := Translation{TenantID: to.TenantID, UserID: to.UserID, Scope: to.Scope}
+		var targetID *int64
+		var oldValue *string
+		if err = rows.Scan(&tr.Namespace, &tr.KeyPath, &tr.Lang, &tr.Variant, &tr.Value, &tr.Attributes, &tr.SourceHash,
+			&tr.EffectiveFrom, &tr.EffectiveUntil, &targetID, &oldValue); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if targetID != nil {
+			updates.Queue(`
+				UPDATE ui_translations SET value = $2, approved_value = $2, status = 'approved', attributes = $3, pending_attributes = NULL,
+					source_hash = COALESCE(NULLIF($4, ''), source_hash),
+					effective_until = $5, pending_effective_until = NULL, updated_at = NOW()
+				WHERE id = $1
+			`, *targetID, tr.Value, tr.attributes(), tr.SourceHash, tr.EffectiveUntil)
+		} else {
+			inserts = append(inserts, tr)
+		}
+		entries = append(entries, auditEntry{action: action, source: from, target: tr, oldValue: oldValue, actor: actor})
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, fmt.Errorf("row iteration error: %w", rows.Err())
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	if updates.Len() > 0 {
+		if err = setTenant(ctx, tx, to.TenantID); err != nil {
+			return 0, err
+		}
+		if err = tx.SendBatch(ctx, updates).Close(); err != nil {
+			return 0, fmt.Errorf("failed to update rows: %w", err)
+		}
+	}
+	if len(inserts) > 0 {
+		if err = copyAndUpsert(ctx, tx, inserts, ""); err != nil {
+			return 0, err
+		}
+	}
+	if err = recordAudit(ctx, tx, entries); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+	return len(entries), nil
+}
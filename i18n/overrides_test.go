@@ -0,0 +1,27 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOverrideScope_Condition(t *testing.T) {
+	var args queryArgs
+	scope := OverrideScope{TenantID: stringPtr("org-1"), Scope: "team:7"}
+	assert.Equal(t,
+		"s.tenant_id IS NOT DISTINCT FROM $1::uuid AND s.user_id IS NOT DISTINCT FROM $2::uuid AND s.scope = $3",
+		scope.condition("s", &args))
+	assert.Equal(t, queryArgs{scope.TenantID, (*string)(nil), "team:7"}, args)
+}
+
+func TestOverrideFilter_Condition(t *testing.T) {
+	var args queryArgs
+	assert.Equal(t, "TRUE", OverrideFilter{}.condition("s", &args))
+	assert.Empty(t, args)
+
+	filter := OverrideFilter{Langs: []string{"en", "es"}, KeyPrefix: "checkout.", Keys: []string{"checkout.pay"}}
+	assert.Equal(t,
+		"TRUE AND s.lang = ANY($1) AND starts_with(s.key_path, $2) AND s.key_path = ANY($3)",
+		filter.condition("s", &args))
+	assert.Equal(t, queryArgs{filter.Langs, "checkout.", filter.Keys}, args)
+}
@@ -0,0 +1,255 @@
+package i18n
+
+import (
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Plural categories can be written two ways. As an ICU plural message in a
+// single value:
+//
+//	{count, plural, one {# plik} few {# pliki} many {# plików} other {# pliku}}
+//
+// or as one key per category, suffixed as in i18next, which Localizer.Format
+// picks from with the "count" argument:
+//
+//	files_one, files_few, files_many, files_other
+//
+// In both cases the category is chosen by the CLDR plural rules of the
+// language, and ValidatePlurals reports categories a language needs but a
+// translation lacks.
+
+// pluralForms maps the x/text plural forms to CLDR category names.
+var pluralForms = map[plural.Form]string{
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+	plural.Other: "other",
+}
+
+func pluralRules(ordinal bool) *plural.Rules {
+	if ordinal {
+		return plural.Ordinal
+	}
+	return plural.Cardinal
+}
+
+// PluralCategory returns the CLDR plural category of n in lang: "zero",
+// "one", "two", "few", "many" or "other". With ordinal it applies the ordinal
+// rules used by selectordinal instead of the cardinal ones.
+func PluralCategory(lang string, n float64, ordinal bool) string {
+	return pluralCategory(lang, n, fractionDigits(n), ordinal)
+}
+
+// pluralCategory is PluralCategory for n written with visible fraction
+// digits, so that "1.0" and "1" may fall in different categories.
+func pluralCategory(lang string, n float64, visible int, ordinal bool) string {
+	i, v, w, f, t := pluralOperands(n, visible)
+	return pluralForms[pluralRules(ordinal).MatchPlural(language.Make(lang), i, v, w, f, t)]
+}
+
+// maxExactInt is the largest integer below which every float64 integer is
+// exact and fits in an int.
+const maxExactInt = 1 << 53
+
+// pluralOperands returns the CLDR operands of n written with visible
+// fraction digits: the integer part i, the number of fraction digits with
+// (v) and without (w) trailing zeros, and the fraction digits with (f) and
+// without (t) trailing zeros. An integer part too large for an int is
+// replaced by one that rules cannot tell apart: it keeps the last six
+// digits, on which rules test at most, and stays above every exact value
+// they compare with.
+func pluralOperands(n float64, visible int) (i, v, w, f, t int) {
+	n = math.Abs(n)
+	whole := math.Trunc(n)
+	if whole < maxExactInt {
+		i = int(whole)
+	} else {
+		i = int(math.Mod(whole, 1_000_000)) + 1_000_000
+	}
+	v = visible
+	f = int(math.Round((n - whole) * math.Pow10(visible)))
+	w, t = v, f
+	for w > 0 && t%10 == 0 {
+		w, t = w-1, t/10
+	}
+	return i, v, w, f, t
+}
+
+// fractionDigits returns the number of fraction digits of n's shortest
+// decimal representation.
+func fractionDigits(n float64) int {
+	s := strconv.FormatFloat(n, 'f', -1, 64)
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		return len(s) - dot - 1
+	}
+	return 0
+}
+
+// visibleFractionDigits returns the fraction digits of v as written: a
+// numeric string keeps its trailing zeros, other numbers use fractionDigits.
+func visibleFractionDigits(v any, n float64) int {
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		if dot := strings.IndexByte(s, '.'); dot >= 0 && !strings.ContainsAny(s, "eE") {
+			return len(s) - dot - 1
+		}
+		return 0
+	}
+	return fractionDigits(n)
+}
+
+var categoryCache sync.Map // categoryCacheKey → []string
+
+type categoryCacheKey struct {
+	lang    string
+	ordinal bool
+}
+
+// PluralCategories returns the categories lang distinguishes, in the order
+// zero, one, two, few, many, other. Every language has "other".
+func PluralCategories(lang string, ordinal bool) []string {
+	key := categoryCacheKey{lang: lang, ordinal: ordinal}
+	if categories, ok := categoryCache.Load(key); ok {
+		return categories.([]string)
+	}
+
+	// The rules only look at the last digits of the integer part and at the
+	// fraction digits, so these samples reach every category.
+	rules, tag := pluralRules(ordinal), language.Make(lang)
+	seen := map[string]bool{}
+	for i := 0; i <= 1000; i++ {
+		seen[pluralForms[rules.MatchPlural(tag, i, 0, 0, 0, 0)]] = true
+	}
+	seen[pluralForms[rules.MatchPlural(tag, 1_000_000, 0, 0, 0, 0)]] = true
+	if !ordinal {
+		for i := 0; i <= 20; i++ {
+			for v, limit := 1, 10; v <= 2; v, limit = v+1, limit*10 {
+				for f := 0; f < limit; f++ {
+					seen[pluralForms[rules.MatchPlural(tag, i, v, 0, f, 0)]] = true
+				}
+			}
+		}
+	}
+
+	var categories []string
+	for _, category := range pluralCategories {
+		if seen[category] {
+			categories = append(categories, category)
+		}
+	}
+	categoryCache.Store(key, categories)
+	return categories
+}
+
+// PluralIssue reports a translation that lacks plural categories its
+// language requires.
+type PluralIssue struct {
+	Namespace string   `json:"namespace"`
+	Lang      string   `json:"lang"`
+	KeyPath   string   `json:"key_path"`           // for suffixed keys, the key without suffix
+	Argument  string   `json:"argument,omitempty"` // plural argument of an ICU message; "" for suffixed keys
+	Ordinal   bool     `json:"ordinal,omitempty"`  // selectordinal argument
+	Missing   []string `json:"missing"`
+}
+
+// ValidatePlurals checks the plural arguments of every ICU message in
+// translations, and every group of suffixed keys (key_one, key_other, ...),
+// against the categories their language requires. An exact "=N" case does
+// not stand in for a category. Values that are not valid messages are
+// skipped; ValidateMessages reports them. Issues are sorted by namespace,
+// language and key.
+func ValidatePlurals(translations []Translation) []PluralIssue {
+	var issues []PluralIssue
+	type groupKey struct{ namespace, lang, base string }
+	groups := map[groupKey]map[string]bool{}
+
+	for _, t := range translations {
+		if base, category, ok := cutPluralSuffix(t.KeyPath); ok {
+			k := groupKey{namespace: t.Namespace, lang: t.Lang, base: base}
+			if groups[k] == nil {
+				groups[k] = map[string]bool{}
+			}
+			groups[k][category] = true
+		}
+
+		m, err := ParseMessage(t.Value)
+		if err != nil {
+			continue
+		}
+		m.walkPlurals(func(p pluralPart) {
+			present := map[string]bool{}
+			for _, c := range p.cases {
+				present[c.selector] = true
+			}
+			if missing := missingCategories(t.Lang, p.ordinal, present); len(missing) > 0 {
+				issues = append(issues, PluralIssue{
+					Namespace: t.Namespace, Lang: t.Lang, KeyPath: t.KeyPath,
+					Argument: p.name, Ordinal: p.ordinal, Missing: missing,
+				})
+			}
+		})
+	}
+
+	for k, present := range groups {
+		if missing := missingCategories(k.lang, false, present); len(missing) > 0 {
+			issues = append(issues, PluralIssue{Namespace: k.namespace, Lang: k.lang, KeyPath: k.base, Missing: missing})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Lang != b.Lang {
+			return a.Lang < b.Lang
+		}
+		return a.KeyPath < b.KeyPath
+	})
+	return issues
+}
+
+func missingCategories(lang string, ordinal bool, present map[string]bool) []string {
+	var missing []string
+	for _, category := range PluralCategories(lang, ordinal) {
+		if !present[category] {
+			missing = append(missing, category)
+		}
+	}
+	return missing
+}
+
+// cutPluralSuffix splits "files_few" into "files" and "few".
+func cutPluralSuffix(keyPath string) (base, category string, ok bool) {
+	i := strings.LastIndexByte(keyPath, '_')
+	if i <= 0 || !isPluralCategory(keyPath[i+1:]) {
+		return "", "", false
+	}
+	return keyPath[:i], keyPath[i+1:], true
+}
+
+// walkPlurals calls fn for every plural and selectordinal argument of m,
+// including nested ones.
+func (m *Message) walkPlurals(fn func(pluralPart)) {
+	for _, part := range m.parts {
+		switch part := part.(type) {
+		case pluralPart:
+			fn(part)
+			for _, c := range part.cases {
+				c.message.walkPlurals(fn)
+			}
+		case selectPart:
+			for _, c := range part.cases {
+				c.message.walkPlurals(fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package i18n
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang    string
+		n       float64
+		ordinal bool
+		want    string
+	}{
+		{"en", 1, false, "one"},
+		{"en", 1.5, false, "other"},
+		{"en", 2, true, "two"},
+		{"en", 11, true, "other"},
+		{"ru", 21, false, "one"},
+		{"ru", 3, false, "few"},
+		{"ru", 11, false, "many"},
+		{"ru", 2.5, false, "other"},
+		{"pl", 22, false, "few"},
+		{"pl", 12, false, "many"},
+		{"ar", 0, false, "zero"},
+		{"ar", 2, false, "two"},
+		{"ar", 105, false, "few"},
+		{"ar", 111, false, "many"},
+		{"ar", 100, false, "other"},
+		{"ja", 1, false, "other"},
+		{"fr", 0, false, "one"},
+		{"en", 10_000_001, false, "other"},
+		{"ru", 10_000_001, false, "one"},
+		{"ru", 1e21, false, "many"},
+		{"is", 11, false, "other"},
+		{"is", 0.3, false, "one"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, PluralCategory(tt.lang, tt.n, tt.ordinal), "%s %v ordinal=%v", tt.lang, tt.n, tt.ordinal)
+	}
+
+	// Visible fraction digits count: "1.0" is not "one" in English
+	assert.Equal(t, "other", pluralCategory("en", 1, 1, false))
+	// but is in Icelandic, where only t, the fraction without trailing zeros, matters
+	assert.Equal(t, "one", pluralCategory("is", 1, 1, false))
+	assert.Equal(t, "one", pluralCategory("is", 21, 2, false))
+
+	// Large integer parts keep their category instead of wrapping around
+	assert.NotEqual(t, "one", PluralCategory("fr", 20_000_000, false))
+}
+
+func TestPluralOperands(t *testing.T) {
+	tests := []struct {
+		n             float64
+		visible       int
+		i, v, w, f, t int
+	}{
+		{1, 0, 1, 0, 0, 0, 0},
+		{1, 2, 1, 2, 0, 0, 0},
+		{1.5, 2, 1, 2, 1, 50, 5},
+		{-1.25, 2, 1, 2, 2, 25, 25},
+		{1.03, 3, 1, 3, 2, 30, 3},
+		{10_000_001, 0, 10_000_001, 0, 0, 0, 0},
+		{1e21, 0, 1_000_000, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		i, v, w, f, tr := pluralOperands(tt.n, tt.visible)
+		assert.Equal(t, []int{tt.i, tt.v, tt.w, tt.f, tt.t}, []int{i, v, w, f, tr}, "%v with %d fraction digits", tt.n, tt.visible)
+	}
+}
+
+func TestPluralCategories(t *testing.T) {
+	assert.Equal(t, []string{"one", "other"}, PluralCategories("en", false))
+	assert.Equal(t, []string{"one", "two", "few", "other"}, PluralCategories("en", true))
+	assert.Equal(t, []string{"one", "few", "many", "other"}, PluralCategories("ru", false))
+	assert.Equal(t, []string{"one", "few", "many", "other"}, PluralCategories("pl", false))
+	assert.Equal(t, []string{"zero", "one", "two", "few", "many", "other"}, PluralCategories("ar", false))
+	assert.Equal(t, []string{"other"}, PluralCategories("ja", false))
+}
+
+func TestMessage_FormatPluralRules(t *testing.T) {
+	m, err := ParseMessage("{n, plural, one {# plik} few {# pliki} many {# plików} other {# pliku}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "1 plik", m.Format("pl", map[string]any{"n": 1}))
+	assert.Equal(t, "23 pliki", m.Format("pl", map[string]any{"n": 23}))
+	assert.Equal(t, "15 plików", m.Format("pl", map[string]any{"n": 15}))
+	assert.Equal(t, "1,5 pliku", m.Format("pl", map[string]any{"n": 1.5}))
+}
+
+func TestValidatePlurals(t *testing.T) {
+	issues := ValidatePlurals([]Translation{
+		{Lang: "en", KeyPath: "files", Value: "{n, plural, one {# file} other {# files}}"},
+		{Lang: "ru", KeyPath: "files", Value: "{n, plural, one {# файл} other {# файлов}}"},
+		{Lang: "en", KeyPath: "place", Value: "{n, selectordinal, one {#st} other {#th}}"},
+		{Lang: "ar", KeyPath: "days", Value: "{n, plural, =0 {لا أيام} one {يوم} two {يومان} few {# أيام} many {# يومًا} other {# يوم}}"},
+		{Lang: "pl", KeyPath: "items_one", Value: "# przedmiot"},
+		{Lang: "pl", KeyPath: "items_few", Value: "# przedmioty"},
+		{Lang: "pl", KeyPath: "items_other", Value: "# przedmiotu"},
+		{Lang: "en", KeyPath: "broken", Value: "{n, plural, one {#}"},
+	})
+	assert.Equal(t, []PluralIssue{
+		{Lang: "ar", KeyPath: "days", Argument: "n", Missing: []string{"zero"}}, // "=0" does not count as "zero"
+		{Lang: "en", KeyPath: "place", Argument: "n", Ordinal: true, Missing: []string{"two", "few"}},
+		{Lang: "pl", KeyPath: "items", Missing: []string{"many"}},
+		{Lang: "ru", KeyPath: "files", Argument: "n", Missing: []string{"few", "many"}},
+	}, issues)
+}
+
+func TestLocalizer_SuffixedPlurals(t *testing.T) {
+	l := NewLocalizer(nil, NewCatalog([]Translation{
+		{Lang: "ru", KeyPath: "files_one", Value: "{count} файл"},
+		{Lang: "ru", KeyPath: "files_few", Value: "{count} файла"},
+		{Lang: "ru", KeyPath: "files_many", Value: "{count} файлов"},
+		{Lang: "ru", KeyPath: "files_other", Value: "{count} файла"},
+		{Lang: "ru", KeyPath: "files_zero", Value: "Нет файлов"},
+	}), nil)
+	ctx := context.Background()
+
+	for count, want := range map[any]string{0: "Нет файлов", 1: "1 файл", 22: "22 файла", 11: "11 файлов", "1.5": "1.5 файла"} {
+		got, err := l.Format(ctx, "files", "ru", map[string]any{"count": count})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := l.Format(ctx, "files", "ru", nil)
+	assert.Error(t, err)
+}
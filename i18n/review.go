@@ -0,0 +1,230 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// ReviewStatus is where a row of ui_translations stands in review. Lookups
+// serve the last approved value of a row, whatever its status, unless
+// WithDrafts is given.
+type ReviewStatus string
+
+const (
+	StatusDraft    ReviewStatus = "draft"     // saved, not submitted yet
+	StatusInReview ReviewStatus = "in_review" // submitted, awaiting Approve or Reject
+	StatusApproved ReviewStatus = "approved"  // the value is the one served
+	StatusRejected ReviewStatus = "rejected"  // the value was turned down; the previous approved one is served
+)
+
+// proposal is how copyAndMerge stores values that are not approved yet.
+type proposal struct {
+	status ReviewStatus
+	author *string
+}
+
+// TranslationRef identifies a row of ui_translations.
+type TranslationRef struct {
+	TenantID  *string `json:"tenant_id,omitempty"`
+	UserID    *string `json:"user_id,omitempty"`
+	Scope     string  `json:"scope,omitempty"`
+	Namespace string  `json:"namespace"`
+	Lang      string  `json:"lang"`
+	KeyPath   string  `json:"key_path"`
+	Variant   string  `json:"variant,omitempty"`
+
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+}
+
+// Ref returns the reference of the row t is stored in.
+func (t Translation) Ref() TranslationRef {
+	return TranslationRef{TenantID: t.TenantID, UserID: t.UserID, Scope: t.Scope, Namespace: t.Namespace, Lang: t.Lang, KeyPath: t.KeyPath,
+		Variant: t.Variant, EffectiveFrom: t.EffectiveFrom}
+}
+
+// translation returns a Translation stored in the row r refers to.
+func (r TranslationRef) translation() Translation {
+	return Translation{TenantID: r.TenantID, UserID: r.UserID, Scope: r.Scope, Namespace: r.Namespace, Lang: r.Lang, KeyPath: r.KeyPath,
+		Variant: r.Variant, EffectiveFrom: r.EffectiveFrom}
+}
+
+// condition returns a SQL condition matching the row r refers to in the
+// table aliased as alias.
+func (r TranslationRef) condition(alias string, args *queryArgs) string {
+	return OverrideScope{TenantID: r.TenantID, UserID: r.UserID, Scope: r.Scope}.condition(alias, args) +
+		" AND " + alias + ".namespace = " + args.add(r.Namespace) +
+		" AND " + alias + ".lang = " + args.add(r.Lang) +
+		" AND " + alias + ".key_path = " + args.add(r.KeyPath) +
+		" AND " + alias + ".variant = " + args.add(r.Variant) +
+		" AND " + alias + ".effective_from IS NOT DISTINCT FROM " + args.add(r.EffectiveFrom) + "::timestamptz"
+}
+
+// SaveDrafts stores translations as drafts by author, without changing the
+// values lookups serve. Rows that were never approved are not served at all
+// until they are. Only WithSourceLang is honoured among the options.
+func SaveDrafts(ctx context.Context, conn *pgx.Conn, translations []Translation, author *string, opts ...LookupOption) error {
+	return propose(ctx, conn, translations, proposal{status: StatusDraft, author: author}, opts)
+}
+
+// SubmitForReview stores translations as changes by author awaiting review,
+// without changing the values lookups serve until Approve is called.
+// Submitting a row again replaces its pending value. Only WithSourceLang is
+// honoured among the options.
+func SubmitForReview(ctx context.Context, conn *pgx.Conn, translations []Translation, author *string, opts ...LookupOption) error {
+	return propose(ctx, conn, translations, proposal{status: StatusInReview, author: author}, opts)
+}
+
+func propose(ctx context.Context, conn *pgx.Conn, translations []Translation, p proposal, opts []LookupOption) error {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = copyAndMerge(ctx, tx, translations, true, &p, newLookupOptions(opts).sourceLang); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Approve makes the pending value, attributes and end of effect of each
+// referenced row in review the ones lookups serve, recording reviewer and
+// comment on the row and an "approve" audit entry with the previously
+// approved value. Rows not in review are skipped. It returns the number of
+// rows approved.
+func Approve(ctx context.Context, conn *pgx.Conn, refs []TranslationRef, reviewer *string, comment string) (int, error) {
+	return review(ctx, conn, "approve", refs, reviewer, comment, `
+		WITH old AS (
+			SELECT id, approved_value FROM ui_translations t
+			WHERE %[1]s AND t.status = 'in_review'
+			FOR UPDATE
+		)
+		UPDATE ui_translations t SET approved_value = t.value, status = 'approved',
+			attributes = COALESCE(t.pending_attributes, t.attributes), pending_attributes = NULL,
+			effective_until = t.pending_effective_until, pending_effective_until = NULL,
+			reviewed_by = %[2]s::uuid, reviewed_at = NOW(), review_comment = %[3]s
+		FROM old WHERE t.id = old.id
+		RETURNING t.value, old.approved_value
+	`)
+}
+
+// Reject turns down the pending value of each referenced row in review,
+// recording reviewer and comment on the row and a "reject" audit entry whose
+// new value is the rejected one. Lookups keep serving the approved value.
+// Rows not in review are skipped. It returns the number of rows rejected.
+func Reject(ctx context.Context, conn *pgx.Conn, refs []TranslationRef, reviewer *string, comment string) (int, error) {
+	return review(ctx, conn, "reject", refs, reviewer, comment, `
+		UPDATE ui_translations t SET status = 'rejected',
+			reviewed_by = %[2]s::uuid, reviewed_at = NOW(), review_comment = %[3]s
+		WHERE %[1]s AND t.status = 'in_review'
+		RETURNING t.value, t.approved_value
+	`)
+}
+
+// review runs query, formatted with the condition matching a row, the
+// reviewer and the comment, for each of refs in one transaction, and audits
+// the rows it returns (value, approved value) under action.
+func review(ctx context.Context, conn *pgx.Conn, action string, refs []TranslationRef, reviewer *string, comment, query string) (int, error) {
+	if len(refs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	targets := make([]Translation, 0, len(refs))
+	for _, ref := range refs {
+		targets = append(targets, ref.translation())
+	}
+	if err = setTenant(ctx, tx, batchTenant(targets)); err != nil {
+		return 0, err
+	}
+
+	var entries []auditEntry
+	for i, ref := range refs {
+		var args queryArgs
+		sql := fmt.Sprintf(query, ref.condition("t", &args), args.add(reviewer), args.add(comment))
+		target := targets[i]
+		var oldValue *string
+		err = tx.QueryRow(ctx, sql, args...).Scan(&target.Value, &oldValue)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s %s: %w", action, target.KeyPath, err)
+		}
+		entries = append(entries, auditEntry{action: action, source: GlobalScope, target: target, oldValue: oldValue, actor: reviewer})
+	}
+
+	if err = recordAudit(ctx, tx, entries); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+	return len(entries), nil
+}
+
+// ReviewItem is a row as ListReviews reports it to reviewers.
+type ReviewItem struct {
+	TranslationRef
+	Status        ReviewStatus `json:"status"`
+	Value         string       `json:"value"`                    // the latest value
+	ApprovedValue *string      `json:"approved_value,omitempty"` // the value served; nil if never approved
+	SubmittedBy   *string      `json:"submitted_by,omitempty"`
+	SubmittedAt   *time.Time   `json:"submitted_at,omitempty"`
+	ReviewedBy    *string      `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time   `json:"reviewed_at,omitempty"`
+	ReviewComment string       `json:"review_comment,omitempty"`
+}
+
+// ListReviews lists the rows of the namespace (the default one without
+// WithNamespace) with the given status, overrides included, oldest
+// submission first. With WithTenant only global rows and that tenant's rows
+// are listed.
+func ListReviews(ctx context.Context, conn *pgx.Conn, status ReviewStatus, opts ...LookupOption) ([]ReviewItem, error) {
+	o := newLookupOptions(opts)
+	const query = `
+		SELECT tenant_id::text, user_id::text, scope, lang, key_path, variant, effective_from, status, value, approved_value,
+			submitted_by::text, submitted_at, reviewed_by::text, reviewed_at, COALESCE(review_comment, '')
+		FROM ui_translations
+		WHERE namespace = $1 AND status = $2 AND ($3::uuid IS NULL OR tenant_id IS NULL OR tenant_id = $3::uuid)
+		ORDER BY submitted_at NULLS FIRST, key_path, lang
+	`
+
+	var reviews []ReviewItem
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, o.namespace, status, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			r := ReviewItem{TranslationRef: TranslationRef{Namespace: o.namespace}}
+			if err = rows.Scan(&r.TenantID, &r.UserID, &r.Scope, &r.Lang, &r.KeyPath, &r.Variant, &r.EffectiveFrom, &r.Status, &r.Value, &r.ApprovedValue,
+				&r.SubmittedBy, &r.SubmittedAt, &r.ReviewedBy, &r.ReviewedAt, &r.ReviewComment); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			reviews = append(reviews, r)
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
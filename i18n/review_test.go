@@ -0,0 +1,26 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTranslationRef(t *testing.T) {
+	tenant, user := "tenant", "user"
+	tr := Translation{TenantID: &tenant, UserID: &user, Scope: "team:7", Namespace: "checkout", Lang: "de", KeyPath: "cart.title", Value: "Warenkorb"}
+
+	ref := tr.Ref()
+	assert.Equal(t, TranslationRef{TenantID: &tenant, UserID: &user, Scope: "team:7", Namespace: "checkout", Lang: "de", KeyPath: "cart.title"}, ref)
+	assert.Equal(t, tr.rowKey(), ref.translation().rowKey())
+
+	var args queryArgs
+	assert.Equal(t, "t.tenant_id IS NOT DISTINCT FROM $1::uuid AND t.user_id IS NOT DISTINCT FROM $2::uuid AND t.scope = $3"+
+		" AND t.namespace = $4 AND t.lang = $5 AND t.key_path = $6 AND t.variant = $7 AND t.effective_from IS NOT DISTINCT FROM $8::timestamptz", ref.condition("t", &args))
+	assert.Equal(t, queryArgs{&tenant, &user, "team:7", "checkout", "de", "cart.title", "", (*time.Time)(nil)}, args)
+}
+
+func TestWithDrafts(t *testing.T) {
+	assert.Equal(t, "approved_value", newLookupOptions(nil).valueColumn())
+	assert.Equal(t, "value", newLookupOptions([]LookupOption{WithDrafts()}).valueColumn())
+}
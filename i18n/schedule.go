@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// latestEffective orders the rows of one key and level so that the value in
+// effect most recently comes first: a scheduled value beats the permanent
+// one once its window opens.
+const latestEffective = "effective_from DESC NULLS LAST"
+
+// atSQL returns the SQL expression of the time a lookup resolves against:
+// the one given with WithTime, else the current time.
+func (o lookupOptions) atSQL(args *queryArgs) string {
+	if o.at == nil {
+		return "NOW()"
+	}
+	return args.add(*o.at) + "::timestamptz"
+}
+
+// activeSQL returns a condition matching rows of the table aliased as alias
+// ("" for none) whose effective window contains at.
+func activeSQL(alias, at string) string {
+	if alias != "" {
+		alias += "."
+	}
+	return fmt.Sprintf("(%[1]seffective_from IS NULL OR %[1]seffective_from <= %[2]s) AND (%[1]seffective_until IS NULL OR %[1]seffective_until > %[2]s)",
+		alias, at)
+}
+
+// NextScheduledChange returns when the translations GetTranslation and
+// ExportToFlatJSON would resolve for lang and userID next change because a
+// scheduled value comes into or goes out of effect, or nil if none is
+// scheduled. It reads the same rows as ExportToFlatJSON with the same
+// options, after the time given with WithTime or now.
+func NextScheduledChange(ctx context.Context, conn *pgx.Conn, lang string, userID *string, opts ...LookupOption) (*time.Time, error) {
+	o := newLookupOptions(opts)
+	var args queryArgs
+	levels := scopeChain(userID, o, o.exportMode, &args)
+	if len(levels) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT MIN(c.at) FROM ui_translations
+		CROSS JOIN LATERAL (VALUES (effective_from), (effective_until)) AS c(at)
+		WHERE lang = ` + args.add(lang) + ` AND ` + scopeFilter(levels) + `
+		AND ` + o.variantSQL(&args) + ` AND namespace = ` + args.add(o.namespace) + ` AND c.at > ` + o.atSQL(&args) + `
+	`
+
+	var next *time.Time
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		return q.QueryRow(ctx, query, args...).Scan(&next)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled changes: %w", err)
+	}
+	return next, nil
+}
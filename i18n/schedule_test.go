@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestActiveSQL(t *testing.T) {
+	assert.Equal(t, "(effective_from IS NULL OR effective_from <= NOW()) AND (effective_until IS NULL OR effective_until > NOW())",
+		activeSQL("", "NOW()"))
+	assert.Equal(t, "(t.effective_from IS NULL OR t.effective_from <= $1) AND (t.effective_until IS NULL OR t.effective_until > $1)",
+		activeSQL("t", "$1"))
+
+	var args queryArgs
+	assert.Equal(t, "NOW()", newLookupOptions(nil).atSQL(&args))
+	assert.Empty(t, args)
+
+	launch := time.Date(2026, 11, 27, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, "$1::timestamptz", newLookupOptions([]LookupOption{WithTime(launch)}).atSQL(&args))
+	assert.Equal(t, queryArgs{launch}, args)
+}
+
+func TestDedupeTranslations_EffectiveFrom(t *testing.T) {
+	launch := time.Date(2026, 11, 27, 9, 0, 0, 0, time.UTC)
+	sameLaunch := launch.In(time.FixedZone("CET", 3600))
+	translations := []Translation{
+		{KeyPath: "banner", Lang: "en", Value: "Shop now"},
+		{KeyPath: "banner", Lang: "en", Value: "Black Friday!", EffectiveFrom: &launch},
+		{KeyPath: "banner", Lang: "en", Value: "Black Friday deals!", EffectiveFrom: &sameLaunch},
+	}
+
+	// A scheduled value is a row of its own; the same instant in another
+	// zone is the same row
+	assert.Equal(t, []Translation{translations[0], translations[2]}, dedupeTranslations(translations))
+}
+
+func TestBundle_Current(t *testing.T) {
+	now := time.Now()
+	assert.True(t, (&bundle{}).current(now))
+	assert.True(t, (&bundle{expires: now.Add(time.Minute)}).current(now))
+	assert.False(t, (&bundle{expires: now}).current(now))
+}
@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scopeLevel is one step of an override hierarchy, matched by a SQL condition
+// on the tenant_id, user_id and scope columns of ui_translations.
+type scopeLevel struct {
+	name      string // "user", "scope", "tenant" or "global"
+	condition string
+}
+
+// queryArgs collects positional arguments while a query is being built.
+type queryArgs []any
+
+// add appends v and returns its placeholder.
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// scopeChain returns the levels a lookup walks, most specific first: the
+// user, then each scope given with WithScopes in order, then the tenant, then
+// the global translation. Levels the mode does not read are left out.
+func scopeChain(userID *string, o lookupOptions, mode ExportMode, args *queryArgs) []scopeLevel {
+	overrides := mode != ExportGlobalsOnly
+	// Postgres cannot infer the type of an unused parameter, so the tenant
+	// is only added once a level refers to it.
+	var tenantArg string
+	tenant := func() string {
+		if tenantArg == "" {
+			tenantArg = args.add(o.tenantID)
+		}
+		return tenantArg
+	}
+	var levels []scopeLevel
+	if overrides && userID != nil {
+		levels = append(levels, scopeLevel{
+			name:      "user",
+			condition: fmt.Sprintf("(user_id = %s AND (tenant_id IS NULL OR tenant_id = %s))", args.add(*userID), tenant()),
+		})
+	}
+	for _, scope := range o.scopes {
+		if !overrides {
+			break
+		}
+		levels = append(levels, scopeLevel{
+			name:      "scope",
+			condition: fmt.Sprintf("(user_id IS NULL AND scope = %s AND tenant_id IS NOT DISTINCT FROM %s)", args.add(scope), tenant()),
+		})
+	}
+	if overrides && o.tenantID != nil {
+		levels = append(levels, scopeLevel{
+			name:      "tenant",
+			condition: fmt.Sprintf("(user_id IS NULL AND scope = '' AND tenant_id = %s)", tenant()),
+		})
+	}
+	if mode != ExportOverridesOnly {
+		levels = append(levels, scopeLevel{
+			name:      "global",
+			condition: "(user_id IS NULL AND scope = '' AND tenant_id IS NULL)",
+		})
+	}
+	return levels
+}
+
+// scopeFilter returns a condition matching any level of the chain.
+func scopeFilter(levels []scopeLevel) string {
+	conditions := make([]string, len(levels))
+	for i, l := range levels {
+		conditions[i] = l.condition
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// scopePrecedence returns an expression evaluating to the index of the most
+// specific level a row belongs to, so ordering by it ascending puts the
+// winning override first.
+func scopePrecedence(levels []scopeLevel) string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	for i, l := range levels {
+		fmt.Fprintf(&sb, " WHEN %s THEN %d", l.condition, i)
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+// ExportMode selects the levels of the scope chain ExportToFlatJSON reads.
+type ExportMode int
+
+const (
+	// ExportEffective merges every level, so each key has the value
+	// GetTranslation would return for it.
+	ExportEffective ExportMode = iota
+	// ExportOverridesOnly returns only keys overridden by the user, a scope or
+	// the tenant, each with its most specific override.
+	ExportOverridesOnly
+	// ExportGlobalsOnly returns only the global translations.
+	ExportGlobalsOnly
+)
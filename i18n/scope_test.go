@@ -0,0 +1,53 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScopeChain(t *testing.T) {
+	var args queryArgs
+	o := newLookupOptions([]LookupOption{WithTenant("org-1"), WithScopes("team:7", "workspace:3")})
+	levels := scopeChain(stringPtr("user-1"), o, ExportEffective, &args)
+
+	names := make([]string, len(levels))
+	for i, l := range levels {
+		names[i] = l.name
+	}
+	assert.Equal(t, []string{"user", "scope", "scope", "tenant", "global"}, names)
+	assert.Equal(t, queryArgs{"user-1", o.tenantID, "team:7", "workspace:3"}, args)
+	assert.Equal(t, "(user_id = $1 AND (tenant_id IS NULL OR tenant_id = $2))", levels[0].condition)
+	assert.Equal(t, "(user_id IS NULL AND scope = $4 AND tenant_id IS NOT DISTINCT FROM $2)", levels[2].condition)
+	assert.Equal(t, "(user_id IS NULL AND scope = '' AND tenant_id = $2)", levels[3].condition)
+}
+
+func TestScopeChain_GlobalOnly(t *testing.T) {
+	var args queryArgs
+	levels := scopeChain(nil, newLookupOptions(nil), ExportEffective, &args)
+
+	assert.Empty(t, args)
+	assert.Len(t, levels, 1)
+	assert.Equal(t, "((user_id IS NULL AND scope = '' AND tenant_id IS NULL))", scopeFilter(levels))
+	assert.Equal(t, "CASE WHEN (user_id IS NULL AND scope = '' AND tenant_id IS NULL) THEN 0 END", scopePrecedence(levels))
+}
+
+func TestScopeChain_ExportModes(t *testing.T) {
+	o := newLookupOptions([]LookupOption{WithTenant("org-1"), WithScopes("team:7")})
+
+	var args queryArgs
+	levels := scopeChain(stringPtr("user-1"), o, ExportOverridesOnly, &args)
+	names := make([]string, len(levels))
+	for i, l := range levels {
+		names[i] = l.name
+	}
+	assert.Equal(t, []string{"user", "scope", "tenant"}, names)
+
+	args = nil
+	levels = scopeChain(stringPtr("user-1"), o, ExportGlobalsOnly, &args)
+	assert.Len(t, levels, 1)
+	assert.Equal(t, "global", levels[0].name)
+	assert.Empty(t, args)
+
+	args = nil
+	assert.Empty(t, scopeChain(nil, newLookupOptions(nil), ExportOverridesOnly, &args))
+}
@@ -0,0 +1,137 @@
+package i18n
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// WithSourceLang makes a write stamp each translation of another language
+// given without a SourceHash with the hash of the current lang value of its
+// key, including one written by the same call, so StaleTranslations flags
+// the row once that value changes. Without it source hashes are stored only
+// as given.
+func WithSourceLang(lang string) LookupOption {
+	return func(o *lookupOptions) {
+		o.sourceLang = lang
+	}
+}
+
+// SourceHash returns the hash of a source-language value that a translation
+// made against it stores in Translation.SourceHash.
+func SourceHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// sourceHashSQL computes SourceHash of a SQL expression in the database.
+func sourceHashSQL(expr string) string {
+	return "encode(sha256(convert_to(" + expr + ", 'UTF8')), 'hex')"
+}
+
+// sourceValueSQL returns a subquery selecting the source-language value a
+// row of the table aliased as alias is translated from: the tenant's own
+// source-language row if it has one, else the global one, as approved and
+// in effect now.
+func sourceValueSQL(alias, langArg string) string {
+	return `(
+		SELECT s.approved_value FROM ui_translations s
+		WHERE s.namespace = ` + alias + `.namespace AND s.key_path = ` + alias + `.key_path AND s.lang = ` + langArg + `
+		AND s.user_id IS NULL AND s.scope = '' AND s.variant = '' AND (s.tenant_id IS NULL OR s.tenant_id = ` + alias + `.tenant_id)
+		AND s.approved_value IS NOT NULL AND ` + activeSQL("s", "NOW()") + `
+		ORDER BY s.tenant_id NULLS LAST, s.` + latestEffective + `
+		LIMIT 1
+	)`
+}
+
+// StaleTranslation is a translation whose source-language value changed
+// after it was translated.
+type StaleTranslation struct {
+	Namespace  string    `json:"namespace"`
+	KeyPath    string    `json:"key_path"`
+	Lang       string    `json:"lang"`
+	TenantID   *string   `json:"tenant_id,omitempty"`
+	UserID     *string   `json:"user_id,omitempty"`
+	Scope      string    `json:"scope,omitempty"`
+	Variant    string    `json:"variant,omitempty"`
+	Value      string    `json:"value"`       // the outdated translation
+	Source     string    `json:"source"`      // the current source-language value
+	SourceHash string    `json:"source_hash"` // SourceHash(Source), to store with the reviewed translation
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// StaleTranslations lists the rows of the namespace (the default one without
+// WithNamespace) whose stored source hash no longer matches the current
+// source-language value, overrides and experiment variants included. Rows
+// without a source hash are never stale; see StampSourceHashes. With
+// WithTenant only global rows and that tenant's rows are listed.
+//
+// Once reviewed, upsert the translation with SourceHash set to the listed
+// one, whether its value changed or not.
+func StaleTranslations(ctx context.Context, conn *pgx.Conn, sourceLang string, opts ...LookupOption) ([]StaleTranslation, error) {
+	o := newLookupOptions(opts)
+	query := `
+		SELECT t.tenant_id::text, t.user_id::text, t.scope, t.variant, t.key_path, t.lang, t.value, src.value, t.updated_at
+		FROM ui_translations t
+		CROSS JOIN LATERAL (SELECT ` + sourceValueSQL("t", "$1") + ` AS value) src
+		WHERE t.lang <> $1 AND t.namespace = $2 AND t.source_hash IS NOT NULL
+		AND ($3::uuid IS NULL OR t.tenant_id IS NULL OR t.tenant_id = $3::uuid)
+		AND t.source_hash <> ` + sourceHashSQL("src.value") + `
+		ORDER BY t.key_path, t.lang
+	`
+
+	var stale []StaleTranslation
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, sourceLang, o.namespace, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			s := StaleTranslation{Namespace: o.namespace}
+			if err = rows.Scan(&s.TenantID, &s.UserID, &s.Scope, &s.Variant, &s.KeyPath, &s.Lang, &s.Value, &s.Source, &s.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			s.SourceHash = SourceHash(s.Source)
+			stale = append(stale, s)
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stale, nil
+}
+
+// StampSourceHashes records the current source-language value as the source
+// of every row in the namespace that has no source hash yet, e.g. once after
+// adding the column to an existing database. It returns the number of rows
+// stamped. With WithTenant only global rows and that tenant's rows are
+// stamped.
+func StampSourceHashes(ctx context.Context, conn *pgx.Conn, sourceLang string, opts ...LookupOption) (int, error) {
+	o := newLookupOptions(opts)
+	query := `
+		UPDATE ui_translations t SET source_hash = ` + sourceHashSQL(sourceValueSQL("t", "$1")) + `
+		WHERE t.lang <> $1 AND t.namespace = $2 AND t.source_hash IS NULL
+		AND ($3::uuid IS NULL OR t.tenant_id IS NULL OR t.tenant_id = $3::uuid)
+		AND ` + sourceValueSQL("t", "$1") + ` IS NOT NULL
+	`
+
+	var stamped int
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		tag, err := q.Exec(ctx, query, sourceLang, o.namespace, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to stamp source hashes: %w", err)
+		}
+		stamped = int(tag.RowsAffected())
+		return nil
+	})
+	return stamped, err
+}
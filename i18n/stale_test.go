@@ -0,0 +1,13 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSourceHash(t *testing.T) {
+	// Must match sourceHashSQL: hex-encoded sha256 of the UTF-8 bytes
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", SourceHash(""))
+	assert.Equal(t, "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969", SourceHash("Hello"))
+	assert.NotEqual(t, SourceHash("Cart"), SourceHash("Cart "))
+}
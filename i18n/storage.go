@@ -2,133 +2,321 @@ package i18n
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"strings"
 	"time"
 )
 
+// querier is the subset of *pgx.Conn and pgx.Tx used by the storage helpers,
+// so the same code can run standalone or inside a caller's transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
 // UpsertTranslations inserts or updates translations in bulk using pgx.
-func UpsertTranslations(ctx context.Context, conn *pgx.Conn, translations []Translation) error {
+// The whole batch is applied in a single transaction. Upserted values are
+// approved and served at once, replacing any change pending review; use
+// SubmitForReview to have them reviewed first. Only WithSourceLang is
+// honoured among the options.
+func UpsertTranslations(ctx context.Context, conn *pgx.Conn, translations []Translation, opts ...LookupOption) error {
 	if len(translations) == 0 {
 		return nil
 	}
 
-	// Create the temporary table if it doesn't exist, including tooltip
-	_, err := conn.Exec(ctx, `
-		CREATE TEMPORARY TABLE IF NOT EXISTS ui_translations_temp (
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = copyAndUpsert(ctx, tx, translations, newLookupOptions(opts).sourceLang); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SeedTranslations inserts translations that are not stored yet and leaves
+// existing rows untouched, so defaults shipped with the binary can be seeded
+// at every startup without overwriting edits made in the database. Only
+// WithSourceLang is honoured among the options.
+func SeedTranslations(ctx context.Context, conn *pgx.Conn, translations []Translation, opts ...LookupOption) error {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = copyAndMerge(ctx, tx, translations, false, nil, newLookupOptions(opts).sourceLang); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// copyAndUpsert merges translations into ui_translations, overwriting
+// existing rows. It must run inside a transaction.
+func copyAndUpsert(ctx context.Context, tx querier, translations []Translation, sourceLang string) error {
+	return copyAndMerge(ctx, tx, translations, true, nil, sourceLang)
+}
+
+// conflictTarget is the unique key of ui_translations. The constraint is
+// declared NULLS NOT DISTINCT, so global rows (NULL tenant_id and user_id)
+// conflict like any other row instead of piling up as duplicates.
+const conflictTarget = "(tenant_id, user_id, scope, namespace, key_path, lang, variant, effective_from)"
+
+// copyAndMerge copies translations into a staging table and inserts them
+// into ui_translations, updating rows that already exist only when overwrite
+// is set. Rows are stored approved, unless p is given: they are then stored
+// as its proposal, leaving the approved value of existing rows untouched.
+// With a sourceLang, rows of other languages without a source hash are
+// stamped with the hash of its current value. When a key appears more than once in translations the last one wins. It
+// must run inside a transaction: the staging table gets a unique name per
+// call and is dropped on commit or rollback, so concurrent or retried
+// imports never see each other's rows.
+func copyAndMerge(ctx context.Context, tx querier, translations []Translation, overwrite bool, p *proposal, sourceLang string) error {
+	translations = dedupeTranslations(translations)
+	if err := setTenant(ctx, tx, batchTenant(translations)); err != nil {
+		return err
+	}
+	staging := pgx.Identifier{"ui_translations_stage_" + strings.ReplaceAll(uuid.NewString(), "-", "")}
+
+	_, err := tx.Exec(ctx, `
+		CREATE TEMPORARY TABLE `+staging.Sanitize()+` (
+			tenant_id UUID,
 			user_id UUID,
+			scope TEXT,
+			namespace TEXT,
 			key_path TEXT,
 			lang TEXT,
+			variant TEXT,
 			value TEXT,
-			tooltip TEXT,
+			attributes JSONB,
+			source_hash TEXT,
+			effective_from TIMESTAMPTZ,
+			effective_until TIMESTAMPTZ,
 			updated_at TIMESTAMP
-		);
+		) ON COMMIT DROP;
 	`)
 	if err != nil {
-		return fmt.Errorf("failed to create temporary table: %w", err)
+		return fmt.Errorf("failed to create staging table: %w", err)
 	}
 
-	// Defer cleanup: Drop the temporary table after the function completes
-	defer func() {
-		_, err := conn.Exec(ctx, "DROP TABLE IF EXISTS ui_translations_temp")
-		if err != nil {
-			// Log the error but do not return it from the deferred function
-			fmt.Printf("failed to drop temporary table: %v\n", err)
-		}
-	}()
-
-	// Prepare rows to be inserted, now including tooltip
+	// Prepare rows to be inserted
 	rows := make([][]any, 0, len(translations))
 	now := time.Now()
 
 	for _, t := range translations {
-		var userID any = nil
+		var tenantID, userID any = nil, nil
+		if t.TenantID != nil {
+			tenantID = *t.TenantID
+		}
 		if t.UserID != nil {
 			userID = *t.UserID
 		}
 		rows = append(rows, []any{
+			tenantID,
 			userID,
+			t.Scope,
+			t.Namespace,
 			t.KeyPath,
 			t.Lang,
+			t.Variant,
 			t.Value,
-			t.ToolTip, // Include the tooltip field
+			t.attributes(),
+			t.SourceHash,
+			t.EffectiveFrom,
+			t.EffectiveUntil,
 			now,
 		})
 	}
 
-	// Perform COPY INTO the temporary table
-	_, err = conn.CopyFrom(
+	// Perform COPY INTO the staging table
+	_, err = tx.CopyFrom(
 		ctx,
-		pgx.Identifier{"ui_translations_temp"},
-		[]string{"user_id", "key_path", "lang", "value", "tooltip", "updated_at"},
+		staging,
+		[]string{"tenant_id", "user_id", "scope", "namespace", "key_path", "lang", "variant", "value", "attributes", "source_hash", "effective_from", "effective_until", "updated_at"},
 		pgx.CopyFromRows(rows),
 	)
 	if err != nil {
-		return fmt.Errorf("copy to temp table failed: %w", err)
+		return fmt.Errorf("copy to staging table failed: %w", err)
 	}
 
-	// Perform the UPSERT operation using the data from the temporary table
-	_, err = conn.Exec(ctx, `
-		INSERT INTO ui_translations (user_id, key_path, lang, value, tooltip, updated_at)
-		SELECT user_id, key_path, lang, value, tooltip, updated_at FROM ui_translations_temp
-		ON CONFLICT (user_id, key_path, lang)
-		DO UPDATE SET value = EXCLUDED.value, tooltip = EXCLUDED.tooltip, updated_at = EXCLUDED.updated_at;
-	`)
-	if err != nil {
-		return fmt.Errorf("upsert from temp table failed: %w", err)
+	// Perform the UPSERT operation using the data from the staging table.
+	// A translation upserted without a source hash is stamped with the
+	// current sourceLang value, if any, else keeps the one it had.
+	// A proposal's attributes and end of effect wait in the pending columns
+	// until approved.
+	columns, values := "attributes, effective_until, status, approved_value", "attributes, effective_until, 'approved', value"
+	onConflict := `DO UPDATE SET value = EXCLUDED.value, approved_value = EXCLUDED.approved_value, status = EXCLUDED.status,
+		attributes = EXCLUDED.attributes, pending_attributes = NULL,
+		source_hash = COALESCE(EXCLUDED.source_hash, ui_translations.source_hash),
+		effective_until = EXCLUDED.effective_until, pending_effective_until = NULL, updated_at = EXCLUDED.updated_at`
+	var args []any
+	if p != nil {
+		columns, values = "pending_attributes, pending_effective_until, status, submitted_by, submitted_at",
+			"attributes, effective_until, $1, $2::uuid, updated_at"
+		onConflict = `DO UPDATE SET value = EXCLUDED.value, status = EXCLUDED.status, pending_attributes = EXCLUDED.pending_attributes,
+			submitted_by = EXCLUDED.submitted_by, submitted_at = EXCLUDED.submitted_at,
+			source_hash = COALESCE(EXCLUDED.source_hash, ui_translations.source_hash),
+			pending_effective_until = EXCLUDED.pending_effective_until, updated_at = EXCLUDED.updated_at`
+		args = append(args, p.status, p.author)
+	}
+	if !overwrite {
+		onConflict = "DO NOTHING"
+	}
+	upsert := func(where, sourceHash string) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ui_translations (tenant_id, user_id, scope, namespace, key_path, lang, variant, value, source_hash,
+				effective_from, updated_at, `+columns+`)
+			SELECT tenant_id, user_id, scope, namespace, key_path, lang, variant, value, `+sourceHash+`,
+				effective_from, updated_at, `+values+`
+			FROM `+staging.Sanitize()+` st
+			WHERE `+where+`
+			ON CONFLICT `+conflictTarget+` `+onConflict, args...)
+		if err != nil {
+			return fmt.Errorf("upsert from staging table failed: %w", err)
+		}
+		return nil
+	}
+	staged := "NULLIF(st.source_hash, '')"
+	if sourceLang == "" {
+		return upsert("TRUE", staged)
 	}
 
-	// No need to manually drop the table here, as it's handled by the deferred function
-	return nil
+	// The source rows go first, in a statement of their own, so that the
+	// hashes of the other languages see source values of the same batch.
+	args = append(args, sourceLang)
+	langArg := fmt.Sprintf("$%d", len(args))
+	if err = upsert("st.lang = "+langArg, staged); err != nil {
+		return err
+	}
+	return upsert("st.lang <> "+langArg, "COALESCE("+staged+", "+sourceHashSQL(sourceValueSQL("st", langArg))+")")
+}
+
+// dedupeTranslations keeps the last translation for each row of the unique
+// key, since a single INSERT ... ON CONFLICT cannot touch a row twice.
+func dedupeTranslations(translations []Translation) []Translation {
+	last := make(map[rowKey]int, len(translations))
+	for i, t := range translations {
+		last[t.rowKey()] = i
+	}
+	if len(last) == len(translations) {
+		return translations
+	}
+	deduped := make([]Translation, 0, len(last))
+	for i, t := range translations {
+		if last[t.rowKey()] == i {
+			deduped = append(deduped, t)
+		}
+	}
+	return deduped
 }
 
 // GetTranslation retrieves a translation with fallback using pgx.
-func GetTranslation(ctx context.Context, conn *pgx.Conn, userID *string, keyPath, lang string) (string, error) {
-	var value string
-	query := `
-		SELECT value FROM ui_translations
-		WHERE (user_id = $1 OR user_id IS NULL)
-		AND key_path = $2 AND lang = $3
-		ORDER BY user_id NULLS LAST
-		LIMIT 1
-	`
-	err := conn.QueryRow(ctx, query, userID, keyPath, lang).Scan(&value)
+// The most specific override wins: the user's, then each scope passed with
+// WithScopes in order, then the tenant's (see WithTenant), then the global
+// translation. Without WithNamespace it searches the default namespace.
+// Only approved values are served unless WithDrafts is given; a row whose
+// change awaits review serves its previously approved value. Only rows in
+// effect now, or at the time given with WithTime, are considered; within a
+// level the value that came into effect last wins.
+func GetTranslation(ctx context.Context, conn *pgx.Conn, userID *string, keyPath, lang string, opts ...LookupOption) (string, error) {
+	value, _, err := lookup(ctx, conn, userID, keyPath, lang, newLookupOptions(opts))
 	return value, err
 }
 
-// ExportToFlatJSON retrieves all translations and returns a flat map using pgx, including tooltips.
-func ExportToFlatJSON(ctx context.Context, conn *pgx.Conn, lang string, userID *string) (map[string]map[string]string, error) {
-	const query = `
-		SELECT key_path, value, tooltip FROM ui_translations
-		WHERE lang = $1 AND (user_id = $2 OR user_id IS NULL)
-		ORDER BY user_id NULLS LAST
+// lookup resolves keyPath as GetTranslation does and also returns the
+// variant of the row it was read from.
+func lookup(ctx context.Context, conn *pgx.Conn, userID *string, keyPath, lang string, o lookupOptions) (string, string, error) {
+	var args queryArgs
+	levels := scopeChain(userID, o, ExportEffective, &args)
+	column := o.valueColumn()
+	query := `
+		SELECT ` + column + `, variant FROM ui_translations
+		WHERE ` + scopeFilter(levels) + ` AND ` + column + ` IS NOT NULL AND ` + activeSQL("", o.atSQL(&args)) + `
+		AND ` + o.variantSQL(&args) + `
+		AND namespace = ` + args.add(o.namespace) + ` AND key_path = ` + args.add(keyPath) + ` AND lang = ` + args.add(lang) + `
+		ORDER BY ` + scopePrecedence(levels) + `, ` + controlLast + `, ` + latestEffective + `
+		LIMIT 1
 	`
+	var value, variant string
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		return q.QueryRow(ctx, query, args...).Scan(&value, &variant)
+	})
+	switch {
+	case err == nil:
+		o.reportUsage(ctx, keyPath, lang)
+	case errors.Is(err, pgx.ErrNoRows):
+		o.reportMissing(ctx, keyPath, lang)
+	}
+	return value, variant, err
+}
 
-	rows, err := conn.Query(ctx, query, lang, userID)
-	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
+// ExportToFlatJSON retrieves all translations and returns a flat map using pgx.
+// Each entry holds the translation under "value" and each of its attributes
+// under the attribute's name, e.g. "tooltip".
+// Each key resolves exactly as GetTranslation would resolve it; use
+// WithExportMode to export only overrides or only global translations.
+// Without WithNamespace it exports the default namespace.
+func ExportToFlatJSON(ctx context.Context, conn *pgx.Conn, lang string, userID *string, opts ...LookupOption) (map[string]map[string]string, error) {
+	o := newLookupOptions(opts)
+	var args queryArgs
+	levels := scopeChain(userID, o, o.exportMode, &args)
+	if len(levels) == 0 {
+		return map[string]map[string]string{}, nil
 	}
-	defer rows.Close()
+	column := o.valueColumn()
+	query := `
+		SELECT DISTINCT ON (key_path) key_path, ` + column + `, ` + o.attributesColumn() + ` FROM ui_translations
+		WHERE lang = ` + args.add(lang) + ` AND ` + scopeFilter(levels) + ` AND ` + column + ` IS NOT NULL
+		AND ` + activeSQL("", o.atSQL(&args)) + ` AND ` + o.variantSQL(&args) + ` AND namespace = ` + args.add(o.namespace) + `
+		ORDER BY key_path, ` + scopePrecedence(levels) + `, ` + controlLast + `, ` + latestEffective + `
+	`
 
 	result := make(map[string]map[string]string, 128) // Preallocate with a reasonable initial capacity
 
-	for {
-		key, value, tooltip := "", "", ""
-		if !rows.Next() {
-			break
-		}
-		if err = rows.Scan(&key, &value, &tooltip); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
 		}
-		// Store both value and tooltip in the result map
-		result[key] = map[string]string{
-			"value":   value,
-			"tooltip": tooltip,
+		defer rows.Close()
+
+		for {
+			key, value := "", ""
+			var attrs Attributes
+			if !rows.Next() {
+				break
+			}
+			if err = rows.Scan(&key, &value, &attrs); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			// Store the value and every attribute in the result map
+			entry := make(map[string]string, len(attrs)+1)
+			for name, attr := range attrs {
+				entry[name] = attr
+			}
+			entry["value"] = value
+			result[key] = entry
 		}
-	}
 
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("row iteration error: %w", rows.Err())
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
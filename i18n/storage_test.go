@@ -5,7 +5,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Setup PostgreSQL connection (use an environment variable for connection string)
@@ -26,11 +29,11 @@ func TestExportToFlatJSON(t *testing.T) {
 
 	// Insert test data into the ui_translations table, including tooltips
 	_, err = conn.Exec(context.Background(), `
-		INSERT INTO ui_translations (user_id, key_path, lang, value, tooltip, updated_at)
+		INSERT INTO ui_translations (user_id, key_path, lang, value, approved_value, attributes, updated_at)
 		VALUES 
-			($1, 'topbar.profile', 'en', 'Profile', 'Your profile', NOW()), 
-			(NULL, 'topbar.profile', 'es', 'Perfil', 'Perfil en español', NOW()), 
-			($1, 'footer.contact', 'en', 'Contact', 'Contact us', NOW())
+			($1, 'topbar.profile', 'en', 'Profile', 'Profile', '{"tooltip": "Your profile"}', NOW()), 
+			(NULL, 'topbar.profile', 'es', 'Perfil', 'Perfil', '{"tooltip": "Perfil en español"}', NOW()), 
+			($1, 'footer.contact', 'en', 'Contact', 'Contact', '{"tooltip": "Contact us"}', NOW())
 	`, user1ID)
 	if err != nil {
 		t.Fatalf("Failed to insert test data: %v", err)
@@ -101,7 +104,7 @@ func TestUpsertTranslations(t *testing.T) {
 	// Verify if the translations and tooltips were inserted
 	var value, tooltip string
 	err = conn.QueryRow(context.Background(), `
-		SELECT value, tooltip FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
+		SELECT value, attributes->>'tooltip' FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
 	`, user1ID, "topbar.profile", "en").Scan(&value, &tooltip)
 	assert.NoError(t, err)
 	assert.Equal(t, "Profile", value)
@@ -117,7 +120,7 @@ func TestUpsertTranslations(t *testing.T) {
 
 	// Verify if the translation and tooltip were updated
 	err = conn.QueryRow(context.Background(), `
-		SELECT value, tooltip FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
+		SELECT value, attributes->>'tooltip' FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
 	`, user1ID, "topbar.profile", "en").Scan(&value, &tooltip)
 	assert.NoError(t, err)
 	assert.Equal(t, "Updated Profile", value)
@@ -136,16 +139,1240 @@ func TestUpsertTranslations(t *testing.T) {
 
 	// Verify bulk insertion with tooltips
 	err = conn.QueryRow(context.Background(), `
-		SELECT value, tooltip FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
+		SELECT value, attributes->>'tooltip' FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
 	`, user1ID, "topbar.welcome", "en").Scan(&value, &tooltip)
 	assert.NoError(t, err)
 	assert.Equal(t, "Welcome", value)
 	assert.Equal(t, "Welcome to our site", tooltip)
 
 	err = conn.QueryRow(context.Background(), `
-		SELECT value, tooltip FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
+		SELECT value, attributes->>'tooltip' FROM ui_translations WHERE user_id = $1 AND key_path = $2 AND lang = $3
 	`, user2ID, "topbar.welcome", "es").Scan(&value, &tooltip)
 	assert.NoError(t, err)
 	assert.Equal(t, "Bienvenido", value)
 	assert.Equal(t, "Bienvenido a nuestro sitio", tooltip)
 }
+
+func TestUpsertTranslations_IsolatedStaging(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	user1ID := uuid.New()
+	user2ID := uuid.New()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE user_id IN ($1, $2)
+		`, user1ID, user2ID)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	// A leftover staging table from an older, failed run must not leak its rows
+	_, err = conn.Exec(context.Background(), `
+		CREATE TEMPORARY TABLE ui_translations_temp (user_id UUID, key_path TEXT, lang TEXT, value TEXT, tooltip TEXT, updated_at TIMESTAMP)
+	`)
+	if err == nil {
+		_, err = conn.Exec(context.Background(), `
+			INSERT INTO ui_translations_temp VALUES ($1, 'stale.key', 'en', 'Stale', '', NOW())
+		`, user2ID)
+	}
+	if err != nil {
+		t.Fatalf("Failed to create leftover table: %v", err)
+	}
+
+	translations := []Translation{
+		{UserID: stringPtr(user1ID.String()), KeyPath: "topbar.profile", Lang: "en", Value: "Profile"},
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+	// Retrying on the same session must succeed as well
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+
+	var count int
+	err = conn.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM ui_translations WHERE user_id = $1
+	`, user2ID).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestNamespaces(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	user1ID := uuid.New()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE user_id = $1
+		`, user1ID)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	translations := []Translation{
+		{UserID: stringPtr(user1ID.String()), Namespace: "admin", KeyPath: "title", Lang: "en", Value: "Admin"},
+		{UserID: stringPtr(user1ID.String()), Namespace: "checkout", KeyPath: "title", Lang: "en", Value: "Checkout"},
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+
+	value, err := GetTranslation(context.Background(), conn, stringPtr(user1ID.String()), "title", "en", WithNamespace("checkout"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Checkout", value)
+
+	exported, err := ExportToFlatJSON(context.Background(), conn, "en", stringPtr(user1ID.String()), WithNamespace("admin"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Admin", exported["title"]["value"])
+
+	_, err = GetTranslation(context.Background(), conn, stringPtr(user1ID.String()), "title", "en")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestGetTranslation_TenantResolution(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	keyPath := "tenant.test." + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE key_path = $1
+		`, keyPath)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	translations := []Translation{
+		{KeyPath: keyPath, Lang: "en", Value: "Global"},
+		{TenantID: stringPtr(tenantID.String()), KeyPath: keyPath, Lang: "en", Value: "Tenant"},
+		{TenantID: stringPtr(tenantID.String()), UserID: stringPtr(userID.String()), KeyPath: keyPath, Lang: "en", Value: "User"},
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+
+	value, err := GetTranslation(context.Background(), conn, nil, keyPath, "en")
+	assert.NoError(t, err)
+	assert.Equal(t, "Global", value)
+
+	value, err = GetTranslation(context.Background(), conn, nil, keyPath, "en", WithTenant(tenantID.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, "Tenant", value)
+
+	value, err = GetTranslation(context.Background(), conn, stringPtr(userID.String()), keyPath, "en", WithTenant(tenantID.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, "User", value)
+
+	// Another tenant only sees the global row
+	value, err = GetTranslation(context.Background(), conn, nil, keyPath, "en", WithTenant(uuid.NewString()))
+	assert.NoError(t, err)
+	assert.Equal(t, "Global", value)
+}
+
+func TestGetTranslation_ScopeHierarchy(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	orgID := uuid.New()
+	keyPath := "scope.test." + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE key_path = $1
+		`, keyPath)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	org := stringPtr(orgID.String())
+	translations := []Translation{
+		{KeyPath: keyPath, Lang: "en", Value: "Global"},
+		{TenantID: org, KeyPath: keyPath, Lang: "en", Value: "Org"},
+		{TenantID: org, Scope: "workspace:3", KeyPath: keyPath, Lang: "en", Value: "Workspace"},
+		{TenantID: org, Scope: "team:7", KeyPath: keyPath, Lang: "en", Value: "Team"},
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+
+	value, err := GetTranslation(context.Background(), conn, nil, keyPath, "en", WithTenant(*org), WithScopes("team:7", "workspace:3"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Team", value)
+
+	value, err = GetTranslation(context.Background(), conn, nil, keyPath, "en", WithTenant(*org), WithScopes("team:8", "workspace:3"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Workspace", value)
+
+	value, err = GetTranslation(context.Background(), conn, nil, keyPath, "en", WithTenant(*org))
+	assert.NoError(t, err)
+	assert.Equal(t, "Org", value)
+}
+
+func TestExportToFlatJSON_Precedence(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	namespace := "export-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	tenant := stringPtr(tenantID.String())
+	user := stringPtr(userID.String())
+	translations := []Translation{
+		{Namespace: namespace, KeyPath: "a", Lang: "en", Value: "Global A"},
+		{Namespace: namespace, KeyPath: "b", Lang: "en", Value: "Global B"},
+		{Namespace: namespace, KeyPath: "c", Lang: "en", Value: "Global C"},
+		{TenantID: tenant, Namespace: namespace, KeyPath: "b", Lang: "en", Value: "Tenant B"},
+		{TenantID: tenant, Namespace: namespace, KeyPath: "c", Lang: "en", Value: "Tenant C"},
+		{TenantID: tenant, UserID: user, Namespace: namespace, KeyPath: "c", Lang: "en", Value: "User C"},
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+
+	opts := []LookupOption{WithTenant(*tenant), WithNamespace(namespace)}
+	exported, err := ExportToFlatJSON(context.Background(), conn, "en", user, opts...)
+	assert.NoError(t, err)
+	assert.Len(t, exported, 3)
+	for key, entry := range exported {
+		value, err := GetTranslation(context.Background(), conn, user, key, "en", opts...)
+		assert.NoError(t, err)
+		assert.Equal(t, value, entry["value"], key)
+	}
+
+	overrides, err := ExportToFlatJSON(context.Background(), conn, "en", user, append(opts, WithExportMode(ExportOverridesOnly))...)
+	assert.NoError(t, err)
+	assert.Len(t, overrides, 2)
+	assert.Equal(t, "Tenant B", overrides["b"]["value"])
+	assert.Equal(t, "User C", overrides["c"]["value"])
+
+	globals, err := ExportToFlatJSON(context.Background(), conn, "en", user, append(opts, WithExportMode(ExportGlobalsOnly))...)
+	assert.NoError(t, err)
+	assert.Len(t, globals, 3)
+	assert.Equal(t, "Global C", globals["c"]["value"])
+}
+
+func TestDiffOverrides(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "diff-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	tenant := stringPtr(uuid.NewString())
+	user := stringPtr(uuid.NewString())
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "a", Lang: "en", Value: "Global A"},
+		{Namespace: namespace, KeyPath: "b", Lang: "en", Value: "Global B"},
+		{Namespace: namespace, KeyPath: "c", Lang: "en", Value: "Global C"},
+		{Namespace: namespace, KeyPath: "e", Lang: "en", Value: "Global E"},
+		{TenantID: tenant, Namespace: namespace, KeyPath: "a", Lang: "en", Value: "Tenant A"},
+		{TenantID: tenant, Namespace: namespace, KeyPath: "b", Lang: "en", Value: "Global B"},
+		{TenantID: tenant, Namespace: namespace, KeyPath: "c", Lang: "en", Value: "Tenant C"},
+		{TenantID: tenant, Scope: "team:7", Namespace: namespace, KeyPath: "c", Lang: "en", Value: "Team C"},
+		{TenantID: tenant, Scope: "team:7", Namespace: namespace, KeyPath: "d", Lang: "en", Value: "Team D"},
+		{TenantID: tenant, UserID: user, Namespace: namespace, KeyPath: "e", Lang: "en", Value: "User E"},
+		{TenantID: tenant, Namespace: namespace, KeyPath: "a", Lang: "de", Value: "Mandant A"},
+	}))
+
+	// b matches the global value and is left out; the most specific override of c wins
+	report, err := DiffOverrides(context.Background(), conn, "en", user, WithTenant(*tenant), WithScopes("team:7"), WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, &DiffReport{Lang: "en", Namespace: namespace, Customizations: []Customization{
+		{KeyPath: "a", Level: "tenant", TenantID: tenant, Value: "Tenant A", Global: stringPtr("Global A")},
+		{KeyPath: "c", Level: "scope", TenantID: tenant, Scope: "team:7", Value: "Team C", Global: stringPtr("Global C")},
+		{KeyPath: "d", Level: "scope", TenantID: tenant, Scope: "team:7", Value: "Team D"},
+		{KeyPath: "e", Level: "user", TenantID: tenant, UserID: user, Value: "User E", Global: stringPtr("Global E")},
+	}}, report)
+
+	// Levels not asked for are ignored
+	report, err = DiffOverrides(context.Background(), conn, "en", nil, WithTenant(*tenant), WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, report.Customizations, 2) {
+		assert.Equal(t, "Tenant A", report.Customizations[0].Value)
+		assert.Equal(t, "Tenant C", report.Customizations[1].Value)
+	}
+
+	// Without any override level there is nothing to compare
+	report, err = DiffOverrides(context.Background(), conn, "en", nil, WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Customizations)
+}
+
+func TestCopyOverridesAndPromote(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	fromTenant := stringPtr(uuid.NewString())
+	toTenant := stringPtr(uuid.NewString())
+	actor := stringPtr(uuid.NewString())
+	namespace := "copy-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err == nil {
+			_, err = conn.Exec(context.Background(), `
+				DELETE FROM ui_translation_audit WHERE namespace = $1
+			`, namespace)
+		}
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	translations := []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Cart"},
+		{TenantID: fromTenant, Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Basket"},
+		{TenantID: fromTenant, Namespace: namespace, KeyPath: "cart.pay", Lang: "en", Value: "Pay up"},
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, translations))
+
+	n, err := CopyOverrides(context.Background(), conn,
+		OverrideScope{TenantID: fromTenant}, OverrideScope{TenantID: toTenant},
+		OverrideFilter{Namespaces: []string{namespace}, KeyPrefix: "cart."}, actor)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	value, err := GetTranslation(context.Background(), conn, nil, "cart.pay", "en", WithTenant(*toTenant), WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Pay up", value)
+
+	// Copying again changes nothing
+	n, err = CopyOverrides(context.Background(), conn,
+		OverrideScope{TenantID: fromTenant}, OverrideScope{TenantID: toTenant},
+		OverrideFilter{Namespaces: []string{namespace}}, actor)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = PromoteToGlobal(context.Background(), conn, OverrideScope{TenantID: fromTenant}, []string{"cart.title"}, actor)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	value, err = GetTranslation(context.Background(), conn, nil, "cart.title", "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Basket", value)
+
+	var audited int
+	err = conn.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM ui_translation_audit WHERE namespace = $1 AND actor = $2
+	`, namespace, *actor).Scan(&audited)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, audited)
+}
+
+func TestUpsertTranslations_GlobalRowsAreUnique(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	keyPath := "global.test." + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE key_path = $1
+		`, keyPath)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{KeyPath: keyPath, Lang: "en", Value: "First"},
+	}))
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{KeyPath: keyPath, Lang: "en", Value: "Second"},
+		{KeyPath: keyPath, Lang: "en", Value: "Third"},
+	}))
+	assert.NoError(t, SeedTranslations(context.Background(), conn, []Translation{
+		{KeyPath: keyPath, Lang: "en", Value: "Seeded"},
+	}))
+
+	var count int
+	var value string
+	err = conn.QueryRow(context.Background(), `
+		SELECT COUNT(*), MAX(value) FROM ui_translations WHERE key_path = $1 AND user_id IS NULL AND tenant_id IS NULL
+	`, keyPath).Scan(&count, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "Third", value)
+}
+
+func TestDedupeTranslations(t *testing.T) {
+	translations := []Translation{
+		{Lang: "en", KeyPath: "a", Value: "first"},
+		{Lang: "en", KeyPath: "b", Value: "b"},
+		{UserID: stringPtr("user-1"), Lang: "en", KeyPath: "a", Value: "mine"},
+		{Lang: "en", KeyPath: "a", Value: "last"},
+	}
+
+	assert.Equal(t, []Translation{
+		{Lang: "en", KeyPath: "b", Value: "b"},
+		{UserID: stringPtr("user-1"), Lang: "en", KeyPath: "a", Value: "mine"},
+		{Lang: "en", KeyPath: "a", Value: "last"},
+	}, dedupeTranslations(translations))
+
+	unique := translations[:3]
+	assert.Equal(t, unique, dedupeTranslations(unique))
+}
+
+func TestRowLevelSecurity(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	// Everything runs in one transaction that is rolled back, policies and
+	// role included, so the database is left as it was.
+	ctx := context.Background()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var enabled bool
+	if err = tx.QueryRow(ctx, `
+		SELECT relrowsecurity FROM pg_class WHERE oid = 'ui_translations'::regclass
+	`).Scan(&enabled); err != nil {
+		t.Fatalf("Failed to check row-level security: %v", err)
+	}
+	if !enabled {
+		migration, err := os.ReadFile("../migrations/002_row_level_security.sql")
+		if err != nil {
+			t.Fatalf("Failed to read migration: %v", err)
+		}
+		body := strings.NewReplacer("BEGIN;", "", "COMMIT;", "").Replace(string(migration))
+		if _, err = tx.Exec(ctx, body); err != nil {
+			t.Fatalf("Failed to apply migration: %v", err)
+		}
+	}
+
+	keyPath := "rls.test." + uuid.NewString()
+	tenantA, tenantB := stringPtr(uuid.NewString()), stringPtr(uuid.NewString())
+	assert.NoError(t, copyAndUpsert(ctx, tx, []Translation{
+		{KeyPath: keyPath, Lang: "en", Value: "Global"},
+		{TenantID: tenantA, KeyPath: keyPath, Lang: "en", Value: "Tenant A"},
+		{TenantID: tenantB, KeyPath: keyPath, Lang: "en", Value: "Tenant B"},
+	}, ""))
+
+	// The connecting role may bypass row-level security, so act as one that cannot
+	var schema string
+	if err = tx.QueryRow(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	role := pgx.Identifier{"i18n_rls_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")}.Sanitize()
+	for _, stmt := range []string{
+		"CREATE ROLE " + role + " NOLOGIN NOBYPASSRLS",
+		"GRANT USAGE ON SCHEMA " + pgx.Identifier{schema}.Sanitize() + " TO " + role,
+		"GRANT SELECT, INSERT ON ui_translations TO " + role,
+		"GRANT USAGE ON SEQUENCE ui_translations_id_seq TO " + role,
+		"SET LOCAL ROLE " + role,
+	} {
+		if _, err = tx.Exec(ctx, stmt); err != nil {
+			t.Fatalf("Failed to set up role: %v", err)
+		}
+	}
+	assert.NoError(t, setTenant(ctx, tx, tenantA))
+
+	// Reads see global rows and the tenant's own, never another tenant's
+	rows, err := tx.Query(ctx, `
+		SELECT value FROM ui_translations WHERE key_path = $1 ORDER BY value
+	`, keyPath)
+	if err != nil {
+		t.Fatalf("Failed to query translations: %v", err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Global", "Tenant A"}, values)
+
+	// Writes must carry the tenant's own id
+	_, err = tx.Exec(ctx, "SAVEPOINT foreign_write")
+	assert.NoError(t, err)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ui_translations (tenant_id, key_path, lang, value) VALUES ($1, $2, 'de', 'Mandant B')
+	`, *tenantB, keyPath)
+	assert.ErrorContains(t, err, "row-level security")
+	_, err = tx.Exec(ctx, "ROLLBACK TO SAVEPOINT foreign_write")
+	assert.NoError(t, err)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ui_translations (tenant_id, key_path, lang, value) VALUES ($1, $2, 'de', 'Mandant A')
+	`, *tenantA, keyPath)
+	assert.NoError(t, err)
+}
+
+func TestKeyMetadataAndTranslatorExport(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "keys-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err == nil {
+			_, err = conn.Exec(context.Background(), `
+				DELETE FROM translation_keys WHERE namespace = $1
+			`, namespace)
+		}
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	err = UpsertKeyMetadata(context.Background(), conn, []KeyMetadata{
+		{Namespace: namespace, KeyPath: "cart.pay", Description: "Checkout button", MaxLength: 8, Tags: []string{"button"}},
+		{Namespace: namespace, KeyPath: "cart.title", Description: "Page title"},
+	})
+	assert.NoError(t, err)
+
+	m, err := GetKeyMetadata(context.Background(), conn, namespace, "cart.pay")
+	assert.NoError(t, err)
+	assert.Equal(t, "Checkout button", m.Description)
+	assert.Equal(t, 8, m.MaxLength)
+
+	buttons, err := ListKeyMetadata(context.Background(), conn, namespace, "button")
+	assert.NoError(t, err)
+	assert.Len(t, buttons, 1)
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "en", Value: "Pay"},
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "de", Value: "Jetzt bezahlen"},
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Cart"},
+	}))
+
+	export, err := ExportForTranslators(context.Background(), conn, "en", "de", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Len(t, export.Entries, 2)
+	assert.Equal(t, "Checkout button", export.Entries[0].Context.Description)
+	assert.True(t, export.Entries[0].TooLong)
+	assert.Nil(t, export.Entries[1].Target)
+
+	assert.NoError(t, DeleteKeyMetadata(context.Background(), conn, namespace, "cart.title"))
+	_, err = GetKeyMetadata(context.Background(), conn, namespace, "cart.title")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestAttributes(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "attributes-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "search.query", Lang: "en", Value: "Search", Attributes: Attributes{
+			AttrPlaceholder: "Search products",
+			AttrAriaLabel:   "Search the catalog",
+		}},
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Cart", ToolTip: "Your cart"},
+	}))
+
+	translations, err := ExportToFlatJSON(context.Background(), conn, "en", nil, WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"search.query": {"value": "Search", "placeholder": "Search products", "aria_label": "Search the catalog"},
+		"cart.title":   {"value": "Cart", "tooltip": "Your cart"},
+	}, translations)
+
+	// Upserting replaces the attributes as a whole
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "search.query", Lang: "en", Value: "Search", Attributes: Attributes{AttrShort: "Find"}},
+	}))
+	translations, err = ExportToFlatJSON(context.Background(), conn, "en", nil, WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"value": "Search", "short": "Find"}, translations["search.query"])
+}
+
+func TestLocalizer_Database(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "localizer-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.items", Lang: "en", Value: "{count, plural, one {# item} other {# items}}"},
+	}))
+	fallback := NewCatalog([]Translation{
+		{Namespace: namespace, KeyPath: "cart.empty", Lang: "en", Value: "Your cart is empty"},
+	})
+	l := NewLocalizer(conn, fallback, nil, WithNamespace(namespace))
+
+	got, err := l.Format(context.Background(), "cart.items", "en", map[string]any{"count": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "3 items", got)
+
+	got, err = l.Format(context.Background(), "cart.empty", "en", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Your cart is empty", got)
+
+	// The cached value is served until the language is invalidated
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.items", Lang: "en", Value: "{count, plural, one {# product} other {# products}}"},
+	}))
+	got, _ = l.Format(context.Background(), "cart.items", "en", map[string]any{"count": 1})
+	assert.Equal(t, "1 item", got)
+	l.Invalidate("en")
+	got, _ = l.Format(context.Background(), "cart.items", "en", map[string]any{"count": 1})
+	assert.Equal(t, "1 product", got)
+}
+
+func TestValidateStoredMessages(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "validate-test-" + uuid.NewString()
+	tenantID := uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.total", Lang: "en", Value: "Total: {amount}"},
+		{Namespace: namespace, KeyPath: "cart.total", Lang: "es", Value: "Total: {importe}"},
+		{Namespace: namespace, KeyPath: "cart.total", Lang: "de", TenantID: &tenantID, Value: "Summe: {amount"},
+	}))
+
+	report, err := ValidateStoredMessages(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, report.Issues, 2) {
+		assert.Equal(t, IssueSyntax, report.Issues[0].Kind)
+		assert.Equal(t, &tenantID, report.Issues[0].TenantID)
+		assert.Equal(t, IssueRenamedPlaceholder, report.Issues[1].Kind)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "coverage-test-" + uuid.NewString()
+	tenantID := uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Cart"},
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "en", Value: "Pay"},
+		{Namespace: namespace, KeyPath: "cart.ok", Lang: "en", Value: "OK"},
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "de", Value: "Warenkorb"},
+		{Namespace: namespace, KeyPath: "cart.ok", Lang: "de", Value: "OK"},
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "de", TenantID: &tenantID, Value: "Bezahlen"},
+	}))
+
+	find := func(report *CoverageReport) LangCoverage {
+		for _, c := range report.Languages {
+			if c.Namespace == namespace && c.Lang == "de" {
+				return c
+			}
+		}
+		t.Fatalf("no coverage for %s", namespace)
+		return LangCoverage{}
+	}
+
+	report, err := Coverage(context.Background(), conn, "en")
+	assert.NoError(t, err)
+	c := find(report)
+	assert.Equal(t, 3, c.Total)
+	assert.Equal(t, 1, c.Translated)
+	assert.Equal(t, 1, c.Identical)
+	assert.Equal(t, 1, c.Missing)
+
+	// The tenant's own translation fills the gap
+	report, err = Coverage(context.Background(), conn, "en", WithTenant(tenantID))
+	assert.NoError(t, err)
+	c = find(report)
+	assert.Equal(t, 2, c.Translated)
+	assert.Equal(t, 0, c.Missing)
+}
+
+func TestStaleTranslations(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "stale-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Cart"},
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "en", Value: "Pay"},
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "fr", Value: "Panier", SourceHash: SourceHash("Cart")},
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "fr", Value: "Payer"},
+	}))
+
+	// The row without a hash is stamped, the other one kept
+	stamped, err := StampSourceHashes(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stamped)
+
+	stale, err := StaleTranslations(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Shopping cart"},
+	}))
+	stale, err = StaleTranslations(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, stale, 1) {
+		assert.Equal(t, "cart.title", stale[0].KeyPath)
+		assert.Equal(t, "Panier", stale[0].Value)
+		assert.Equal(t, "Shopping cart", stale[0].Source)
+		assert.Equal(t, SourceHash("Shopping cart"), stale[0].SourceHash)
+	}
+
+	// Upserting without a hash keeps the stale one; the reviewed value clears it
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "fr", Value: "Le panier"},
+	}))
+	stale, err = StaleTranslations(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Len(t, stale, 1)
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "fr", Value: "Panier", SourceHash: stale[0].SourceHash},
+	}))
+	stale, err = StaleTranslations(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+
+	// WithSourceLang, writes without a hash are stamped with the current source,
+	// including one written in the same batch
+	sourceHash := func(lang string) *string {
+		var hash *string
+		err := conn.QueryRow(context.Background(), `
+			SELECT source_hash FROM ui_translations WHERE namespace = $1 AND key_path = 'cart.checkout' AND lang = $2
+		`, namespace, lang).Scan(&hash)
+		assert.NoError(t, err)
+		return hash
+	}
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.checkout", Lang: "fr", Value: "Paiement"},
+		{Namespace: namespace, KeyPath: "cart.checkout", Lang: "en", Value: "Checkout"},
+	}, WithSourceLang("en")))
+	if hash := sourceHash("fr"); assert.NotNil(t, hash) {
+		assert.Equal(t, SourceHash("Checkout"), *hash)
+	}
+	assert.Nil(t, sourceHash("en"), "source-language rows have no source")
+
+	imp := NewImport()
+	imp.Add(
+		Translation{Namespace: namespace, KeyPath: "cart.checkout", Lang: "fr", Value: "Passer la commande"},
+		Translation{Namespace: namespace, KeyPath: "cart.checkout", Lang: "en", Value: "Check out"},
+	)
+	assert.NoError(t, imp.Commit(context.Background(), conn, WithSourceLang("en")))
+	if hash := sourceHash("fr"); assert.NotNil(t, hash) {
+		assert.Equal(t, SourceHash("Check out"), *hash)
+	}
+	stale, err = StaleTranslations(context.Background(), conn, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+}
+
+func TestReviewWorkflow(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "review-test-" + uuid.NewString()
+	translator, reviewer := uuid.NewString(), uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translation_audit WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up audit entries: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "de", Value: "Einkaufswagen", ToolTip: "Ihr Einkaufswagen"},
+	}))
+	title := Translation{Namespace: namespace, KeyPath: "cart.title", Lang: "de", Value: "Warenkorb", ToolTip: "Ihr Warenkorb"}
+	pay := Translation{Namespace: namespace, KeyPath: "cart.pay", Lang: "de", Value: "Bezahlen"}
+	assert.NoError(t, SubmitForReview(context.Background(), conn, []Translation{title, pay}, &translator))
+
+	// Lookups keep serving the approved value and skip rows never approved
+	value, err := GetTranslation(context.Background(), conn, nil, "cart.title", "de", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Einkaufswagen", value)
+	_, err = GetTranslation(context.Background(), conn, nil, "cart.pay", "de", WithNamespace(namespace))
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	value, err = GetTranslation(context.Background(), conn, nil, "cart.title", "de", WithNamespace(namespace), WithDrafts())
+	assert.NoError(t, err)
+	assert.Equal(t, "Warenkorb", value)
+
+	// Attributes wait for approval as well
+	translations, err := ExportToFlatJSON(context.Background(), conn, "de", nil, WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"cart.title": {"value": "Einkaufswagen", "tooltip": "Ihr Einkaufswagen"},
+	}, translations)
+	// but previews show the pending ones
+	translations, err = ExportToFlatJSON(context.Background(), conn, "de", nil, WithNamespace(namespace), WithDrafts())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"cart.title": {"value": "Warenkorb", "tooltip": "Ihr Warenkorb"},
+		"cart.pay":   {"value": "Bezahlen"},
+	}, translations)
+
+	pending, err := ListReviews(context.Background(), conn, StatusInReview, WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, &translator, pending[0].SubmittedBy)
+		assert.NotNil(t, pending[0].SubmittedAt)
+	}
+
+	approved, err := Approve(context.Background(), conn, []TranslationRef{title.Ref()}, &reviewer, "ok")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, approved)
+	rejected, err := Reject(context.Background(), conn, []TranslationRef{pay.Ref(), title.Ref()}, &reviewer, "too formal")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rejected, "the approved row is no longer in review")
+
+	value, err = GetTranslation(context.Background(), conn, nil, "cart.title", "de", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Warenkorb", value)
+	_, err = GetTranslation(context.Background(), conn, nil, "cart.pay", "de", WithNamespace(namespace))
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	translations, err = ExportToFlatJSON(context.Background(), conn, "de", nil, WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"value": "Warenkorb", "tooltip": "Ihr Warenkorb"}, translations["cart.title"])
+
+	rejectedRows, err := ListReviews(context.Background(), conn, StatusRejected, WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, rejectedRows, 1) {
+		assert.Equal(t, "cart.pay", rejectedRows[0].KeyPath)
+		assert.Equal(t, &reviewer, rejectedRows[0].ReviewedBy)
+		assert.Equal(t, "too formal", rejectedRows[0].ReviewComment)
+		assert.Nil(t, rejectedRows[0].ApprovedValue)
+	}
+
+	var oldValue *string
+	err = conn.QueryRow(context.Background(), `
+		SELECT old_value FROM ui_translation_audit WHERE namespace = $1 AND action = 'approve'
+	`, namespace).Scan(&oldValue)
+	assert.NoError(t, err)
+	if assert.NotNil(t, oldValue) {
+		assert.Equal(t, "Einkaufswagen", *oldValue)
+	}
+}
+
+func TestScheduledTranslations(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "schedule-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translation_audit WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up audit entries: %v", err)
+		}
+	}()
+
+	launch := time.Now().Add(time.Hour).Truncate(time.Second)
+	end := launch.Add(24 * time.Hour)
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "banner", Lang: "en", Value: "Shop now"},
+		{Namespace: namespace, KeyPath: "banner", Lang: "en", Value: "Black Friday!", EffectiveFrom: &launch, EffectiveUntil: &end},
+	}))
+
+	// The scheduled value is stored beside the current one and only served within its window
+	value, err := GetTranslation(context.Background(), conn, nil, "banner", "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop now", value)
+	value, err = GetTranslation(context.Background(), conn, nil, "banner", "en", WithNamespace(namespace), WithTime(launch))
+	assert.NoError(t, err)
+	assert.Equal(t, "Black Friday!", value)
+	exported, err := ExportToFlatJSON(context.Background(), conn, "en", nil, WithNamespace(namespace), WithTime(end))
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop now", exported["banner"]["value"])
+
+	next, err := NextScheduledChange(context.Background(), conn, "en", nil, WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.NotNil(t, next) {
+		assert.True(t, launch.Equal(*next))
+	}
+	next, err = NextScheduledChange(context.Background(), conn, "en", nil, WithNamespace(namespace), WithTime(launch))
+	assert.NoError(t, err)
+	if assert.NotNil(t, next) {
+		assert.True(t, end.Equal(*next))
+	}
+	next, err = NextScheduledChange(context.Background(), conn, "en", nil, WithNamespace(namespace), WithTime(end))
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	// The Localizer caches the language until the launch, then reloads it
+	l := NewLocalizer(conn, nil, nil, WithNamespace(namespace))
+	value, err = l.Lookup(context.Background(), "banner", "en")
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop now", value)
+	l.mu.Lock()
+	assert.True(t, launch.Equal(l.bundles["en"].expires))
+	l.bundles["en"].expires = time.Now() // as if the launch had come
+	l.mu.Unlock()
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "banner", Lang: "en", Value: "Black Friday!", EffectiveFrom: &launch, EffectiveUntil: &end},
+		{Namespace: namespace, KeyPath: "banner", Lang: "en", Value: "Shop the sale"},
+	}))
+	value, err = l.Lookup(context.Background(), "banner", "en")
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop the sale", value)
+
+	// A submitted end of effect waits for approval like the value
+	extended := end.Add(24 * time.Hour)
+	sale := Translation{Namespace: namespace, KeyPath: "banner", Lang: "en", Value: "Black Friday!", EffectiveFrom: &launch, EffectiveUntil: &extended}
+	assert.NoError(t, SubmitForReview(context.Background(), conn, []Translation{sale}, nil))
+	value, err = GetTranslation(context.Background(), conn, nil, "banner", "en", WithNamespace(namespace), WithTime(end))
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop the sale", value)
+	_, err = Approve(context.Background(), conn, []TranslationRef{sale.Ref()}, nil, "")
+	assert.NoError(t, err)
+	value, err = GetTranslation(context.Background(), conn, nil, "banner", "en", WithNamespace(namespace), WithTime(end))
+	assert.NoError(t, err)
+	assert.Equal(t, "Black Friday!", value)
+}
+
+func TestVariants(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	namespace := "variant-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cta.buy", Lang: "en", Value: "Buy now"},
+		{Namespace: namespace, KeyPath: "cta.cancel", Lang: "en", Value: "Cancel"},
+		{Namespace: namespace, KeyPath: "cta.buy", Lang: "en", Variant: "urgent", Value: "Get yours before it's gone"},
+	}))
+
+	exp := Experiment{ID: "cta", Variants: []Variant{{Name: ControlVariant, Weight: 1}, {Name: "urgent", Weight: 1}}}
+	subjects := map[string]string{}
+	for i := 0; len(subjects) < 2; i++ {
+		subject := uuid.NewString()
+		subjects[exp.Assign(subject)] = subject
+	}
+
+	// Lookups without a variant serve the control
+	value, err := GetTranslation(context.Background(), conn, nil, "cta.buy", "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Buy now", value)
+
+	value, served, err := GetVariantTranslation(context.Background(), conn, exp, subjects["urgent"], nil, "cta.buy", "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Get yours before it's gone", value)
+	assert.Equal(t, "urgent", served)
+
+	// Keys the variant lacks fall back to the control
+	value, served, err = GetVariantTranslation(context.Background(), conn, exp, subjects["urgent"], nil, "cta.cancel", "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Cancel", value)
+	assert.Equal(t, ControlVariant, served)
+
+	value, served, err = GetVariantTranslation(context.Background(), conn, exp, subjects[ControlVariant], nil, "cta.buy", "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, "Buy now", value)
+	assert.Equal(t, ControlVariant, served)
+
+	exported, err := ExportToFlatJSON(context.Background(), conn, "en", nil, WithNamespace(namespace), WithVariant("urgent"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Get yours before it's gone", exported["cta.buy"]["value"])
+	assert.Equal(t, "Cancel", exported["cta.cancel"]["value"])
+
+	// Drafts and expired values of a variant are not served, so they are not reported
+	expired := time.Now().Add(-time.Hour)
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cta.help", Lang: "en", Variant: "urgent", Value: "Get help now!", EffectiveUntil: &expired},
+	}))
+	assert.NoError(t, SaveDrafts(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cta.cancel", Lang: "en", Variant: "urgent", Value: "Cancel now!"},
+	}, nil))
+	report, err := ExportVariantAssignments(context.Background(), conn, exp, []string{subjects["urgent"], subjects[ControlVariant]}, "en", WithNamespace(namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, []VariantAssignment{
+		{SubjectID: subjects["urgent"], Variant: "urgent"},
+		{SubjectID: subjects[ControlVariant], Variant: ControlVariant},
+	}, report.Assignments)
+	if assert.Len(t, report.Variants, 2) {
+		assert.Equal(t, []string{"cta.buy", "cta.cancel"}, report.Variants[0].Keys)
+		assert.Equal(t, []string{"cta.buy"}, report.Variants[1].Keys)
+		assert.Equal(t, 1, report.Variants[1].Subjects)
+	}
+}
+
+func TestMissingKeyRecorder(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+	recorderConn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer recorderConn.Close(context.Background())
+
+	namespace := "missing-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_missing_keys WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up missing keys: %v", err)
+		}
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	recorder := NewMissingKeyRecorder(recorderConn)
+	opts := []LookupOption{WithNamespace(namespace), WithMissingKeyHandler(recorder.Record)}
+	for i := 0; i < 3; i++ {
+		_, err = GetTranslation(context.Background(), conn, nil, "cart.title", "de", opts...)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	}
+	_, err = GetTranslation(context.Background(), conn, nil, "cart.pay", "de", opts...)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.NoError(t, recorder.Flush(context.Background()))
+
+	// Counts of later flushes add up
+	_, err = GetTranslation(context.Background(), conn, nil, "cart.title", "de", opts...)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.NoError(t, recorder.Flush(context.Background()))
+
+	missing, err := ListMissingKeys(context.Background(), conn, WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, missing, 2) {
+		assert.Equal(t, "cart.title", missing[0].KeyPath)
+		assert.Equal(t, int64(4), missing[0].Count)
+		assert.True(t, missing[0].LastSeen.After(missing[0].FirstSeen))
+		assert.Equal(t, "cart.pay", missing[1].KeyPath)
+		assert.Equal(t, int64(1), missing[1].Count)
+	}
+
+	// Keys translated since are no longer listed; drafts, variants and overrides
+	// the missed lookup would not resolve are not served, so they do not count
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "de", Value: "Warenkorb"},
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "de", Variant: "b", Value: "Jetzt zahlen"},
+	}))
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{TenantID: stringPtr(uuid.NewString()), Namespace: namespace, KeyPath: "cart.pay", Lang: "de", Value: "Zahlen"},
+	}))
+	assert.NoError(t, SaveDrafts(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.pay", Lang: "de", Value: "Bezahlen"},
+	}, nil))
+	missing, err = ListMissingKeys(context.Background(), conn, WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, missing, 1) {
+		assert.Equal(t, "cart.pay", missing[0].KeyPath)
+	}
+}
+
+func TestKeyUsageAndDeadKeys(t *testing.T) {
+	conn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+	recorderConn, err := pgx.Connect(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer recorderConn.Close(context.Background())
+
+	namespace := "usage-test-" + uuid.NewString()
+
+	defer func() {
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_key_usage WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up key usage: %v", err)
+		}
+		_, err = conn.Exec(context.Background(), `
+			DELETE FROM ui_translations WHERE namespace = $1
+		`, namespace)
+		if err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}()
+
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "en", Value: "Cart"},
+		{Namespace: namespace, KeyPath: "cart.title", Lang: "de", Value: "Warenkorb"},
+		{Namespace: namespace, KeyPath: "promo.old", Lang: "en", Value: "Summer sale"},
+		{Namespace: namespace, KeyPath: "files_one", Lang: "en", Value: "{count} file"},
+		{Namespace: namespace, KeyPath: "files_other", Lang: "en", Value: "{count} files"},
+	}))
+	_, err = conn.Exec(context.Background(), `
+		UPDATE ui_translations SET created_at = NOW() - INTERVAL '90 days' WHERE namespace = $1
+	`, namespace)
+	assert.NoError(t, err)
+	assert.NoError(t, UpsertTranslations(context.Background(), conn, []Translation{
+		{Namespace: namespace, KeyPath: "cart.new", Lang: "en", Value: "New"},
+		{Namespace: namespace, KeyPath: "promo.old", Lang: "en", Value: "Summer sale!"},
+	}))
+
+	recorder := NewKeyUsageRecorder(recorderConn)
+	opts := []LookupOption{WithNamespace(namespace), WithKeyUsageHandler(recorder.Record)}
+	_, err = GetTranslation(context.Background(), conn, nil, "cart.title", "en", opts...)
+	assert.NoError(t, err)
+	l := NewLocalizer(conn, nil, nil, opts...)
+	_, err = l.Lookup(context.Background(), "cart.title", "de")
+	assert.NoError(t, err)
+	_, err = l.Format(context.Background(), "files", "en", map[string]any{"count": 2})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Flush(context.Background()))
+
+	var count int64
+	err = conn.QueryRow(context.Background(), `
+		SELECT count FROM ui_key_usage WHERE namespace = $1 AND key_path = 'cart.title'
+	`, namespace).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// promo.old was never served, however recently edited; cart.new is too
+	// recent to judge; files_one is used along with files_other
+	report, err := DeadKeys(context.Background(), conn, time.Now().Add(-time.Hour), WithNamespace(namespace))
+	assert.NoError(t, err)
+	if assert.Len(t, report.Keys, 1) {
+		assert.Equal(t, "promo.old", report.Keys[0].KeyPath)
+		assert.Equal(t, 1, report.Keys[0].Rows)
+		assert.Nil(t, report.Keys[0].LastAccessed)
+	}
+}
@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+)
+
+// TenantSetting is the setting the row-level security policies in
+// migrations/002_row_level_security.sql compare tenant_id against. Calls that
+// act for a single tenant set it for the duration of their transaction, so
+// the database only lets them see global rows and that tenant's rows.
+const TenantSetting = "app.tenant_id"
+
+// setTenant sets TenantSetting to tenantID until the end of the transaction.
+// A nil tenantID leaves the setting unset, which the policies treat as
+// "global rows only".
+func setTenant(ctx context.Context, tx querier, tenantID *string) error {
+	if tenantID == nil {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, "SELECT set_config($1, $2, true)", TenantSetting, *tenantID); err != nil {
+		return fmt.Errorf("failed to set %s: %w", TenantSetting, err)
+	}
+	return nil
+}
+
+// inTenant runs fn in a transaction scoped to tenantID with setTenant. Without
+// a tenant fn runs on conn directly, saving the transaction round trips.
+func inTenant(ctx context.Context, conn *pgx.Conn, tenantID *string, fn func(q querier) error) error {
+	if tenantID == nil {
+		return fn(conn)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = setTenant(ctx, tx, tenantID); err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// batchTenant returns the tenant shared by every translation, or nil if the
+// batch holds global rows or rows of several tenants. Such batches can only
+// be written by a role that bypasses row-level security.
+func batchTenant(translations []Translation) *string {
+	var tenantID *string
+	for _, t := range translations {
+		if t.TenantID == nil || (tenantID != nil && *tenantID != *t.TenantID) {
+			return nil
+		}
+		tenantID = t.TenantID
+	}
+	return tenantID
+}
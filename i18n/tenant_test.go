@@ -0,0 +1,19 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBatchTenant(t *testing.T) {
+	org1, org2 := stringPtr("org-1"), stringPtr("org-2")
+
+	assert.Nil(t, batchTenant(nil))
+	assert.Equal(t, org1, batchTenant([]Translation{
+		{TenantID: org1, KeyPath: "a"},
+		{TenantID: stringPtr("org-1"), UserID: stringPtr("user-1"), KeyPath: "b"},
+	}))
+	assert.Nil(t, batchTenant([]Translation{{TenantID: org1}, {TenantID: org2}}))
+	assert.Nil(t, batchTenant([]Translation{{TenantID: org1}, {}}))
+	assert.Nil(t, batchTenant([]Translation{{}, {TenantID: org1}}))
+}
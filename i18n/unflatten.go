@@ -29,12 +29,12 @@ func transformMapToJSON(input map[string]map[string]string) (string, error) {
 
 		// The last part of the key is the field
 		field := parts[len(parts)-1]
-		// Add the "value" and "tooltip" to the field
+		// Add the "value" to the field and each attribute as field_<name>
 		for key, val := range valueMap {
 			if key == "value" {
 				currentMap[field] = val
-			} else if key == "tooltip" {
-				currentMap[field+"_tooltip"] = val
+			} else {
+				currentMap[field+"_"+key] = val
 			}
 		}
 	}
@@ -163,6 +163,25 @@ func TestTransformMapToJSON(t *testing.T) {
       }
     }
   }
+}`
+	jsonResult, err = transformMapToJSON(input)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, jsonResult)
+
+	// Test 8: Every attribute gets its own suffix
+	input = map[string]map[string]string{
+		"search.query": {
+			"value":       "Search",
+			"placeholder": "Search products",
+			"aria_label":  "Search the catalog",
+		},
+	}
+	expected = `{
+  "search": {
+    "query": "Search",
+    "query_placeholder": "Search products",
+    "query_aria_label": "Search the catalog"
+  }
 }`
 	jsonResult, err = transformMapToJSON(input)
 	assert.NoError(t, err)
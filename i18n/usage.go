@@ -0,0 +1,256 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// KeyUsage is a lookup that found a value for its key.
+type KeyUsage struct {
+	Namespace string
+	KeyPath   string
+	Lang      string
+}
+
+// KeyUsageHandler is called by lookups that find a value; see
+// WithKeyUsageHandler. It runs on the caller's goroutine, often on a hot
+// path, so it should return quickly.
+type KeyUsageHandler func(ctx context.Context, key KeyUsage)
+
+// WithKeyUsageHandler makes GetTranslation, GetVariantTranslation and a
+// Localizer's Lookup and Format call h whenever a key is served, including
+// from the Localizer's cache. Use a KeyUsageRecorder's Record method to
+// track which keys are still in use.
+func WithKeyUsageHandler(h KeyUsageHandler) LookupOption {
+	return func(o *lookupOptions) {
+		o.keyUsage = h
+	}
+}
+
+// reportUsage calls the usage handler of o, if any, for keyPath in lang.
+// Plural variants (keyPath_one, keyPath_other, ...) are reported under their
+// base key, so serving any of them marks the whole group as used.
+func (o lookupOptions) reportUsage(ctx context.Context, keyPath, lang string) {
+	if o.keyUsage == nil {
+		return
+	}
+	if base, _, ok := cutPluralSuffix(keyPath); ok {
+		keyPath = base
+	}
+	o.keyUsage(ctx, KeyUsage{Namespace: o.namespace, KeyPath: keyPath, Lang: lang})
+}
+
+// keyUsageRow identifies a row of ui_key_usage.
+type keyUsageRow struct {
+	namespace string
+	keyPath   string
+}
+
+// usageCount aggregates the accesses of one key between flushes.
+type usageCount struct {
+	count        int64
+	lastAccessed time.Time
+}
+
+// KeyUsageRecorder counts key accesses in memory and adds them to
+// ui_key_usage in batches, one row per namespace and key with the number of
+// accesses and when the key was last served, in any language. Record is
+// cheap and safe for concurrent use; call Flush periodically or let Run do
+// it, so lookups never wait for the database. Give the recorder a connection
+// of its own, since a *pgx.Conn cannot be shared by concurrent callers.
+type KeyUsageRecorder struct {
+	conn *pgx.Conn
+
+	mu      sync.Mutex
+	pending map[keyUsageRow]*usageCount
+
+	flushMu sync.Mutex
+}
+
+// NewKeyUsageRecorder returns a recorder writing to conn.
+func NewKeyUsageRecorder(conn *pgx.Conn) *KeyUsageRecorder {
+	return &KeyUsageRecorder{conn: conn, pending: map[keyUsageRow]*usageCount{}}
+}
+
+// Record notes one access of a key. Its signature matches KeyUsageHandler,
+// so r.Record can be passed to WithKeyUsageHandler.
+func (r *KeyUsageRecorder) Record(_ context.Context, key KeyUsage) {
+	r.add(key, time.Now())
+}
+
+func (r *KeyUsageRecorder) add(key KeyUsage, accessed time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.merge(keyUsageRow{namespace: key.Namespace, keyPath: key.KeyPath}, &usageCount{count: 1, lastAccessed: accessed})
+}
+
+// merge adds c to the pending counts of row. r.mu must be held.
+func (r *KeyUsageRecorder) merge(row keyUsageRow, c *usageCount) {
+	p, ok := r.pending[row]
+	if !ok {
+		r.pending[row] = c
+		return
+	}
+	p.count += c.count
+	if c.lastAccessed.After(p.lastAccessed) {
+		p.lastAccessed = c.lastAccessed
+	}
+}
+
+// Flush writes the accesses recorded since the last flush in one
+// transaction. If it fails they are kept for the next flush.
+func (r *KeyUsageRecorder) Flush(ctx context.Context) error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = map[keyUsageRow]*usageCount{}
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := r.write(ctx, pending); err != nil {
+		r.mu.Lock()
+		for row, c := range pending {
+			r.merge(row, c)
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (r *KeyUsageRecorder) write(ctx context.Context, pending map[keyUsageRow]*usageCount) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for row, c := range pending {
+		batch.Queue(`
+			INSERT INTO ui_key_usage (namespace, key_path, count, last_accessed)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (namespace, key_path) DO UPDATE SET
+				count = ui_key_usage.count + EXCLUDED.count,
+				last_accessed = GREATEST(ui_key_usage.last_accessed, EXCLUDED.last_accessed)
+		`, row.namespace, row.keyPath, c.count, c.lastAccessed)
+	}
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record key usage: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// Run flushes the recorder every interval until ctx is done, then flushes
+// one last time within a second. It returns the error of that last flush;
+// errors of periodic flushes are retried at the next tick.
+func (r *KeyUsageRecorder) Run(ctx context.Context, interval time.Duration) error {
+	return runFlushes(ctx, interval, r.Flush)
+}
+
+// DeadKey is a key not served within the window of a DeadKeyReport.
+type DeadKey struct {
+	KeyPath      string     `json:"key_path"`
+	Rows         int        `json:"rows"`                    // stored rows: languages, overrides, variants
+	Count        int64      `json:"count"`                   // accesses ever recorded
+	LastAccessed *time.Time `json:"last_accessed,omitempty"` // nil if never recorded
+}
+
+// DeadKeyReport lists the keys of a namespace that are candidates for
+// deletion.
+type DeadKeyReport struct {
+	Namespace string    `json:"namespace"`
+	Since     time.Time `json:"since"`
+	Keys      []DeadKey `json:"keys"`
+}
+
+// DeadKeys reports the keys stored in the namespace (the default one
+// without WithNamespace) that were not accessed since since, according to
+// ui_key_usage, oldest access first. A plural variant counts as accessed
+// whenever its base key is. Keys whose rows were all created since
+// then are too recent to judge and left out. With WithTenant only global
+// rows and that tenant's rows count. The report is only as good as the
+// tracking: record usage from every service that reads the namespace for the
+// whole window first. It marshals to JSON as is and renders as a table with
+// WriteText.
+func DeadKeys(ctx context.Context, conn *pgx.Conn, since time.Time, opts ...LookupOption) (*DeadKeyReport, error) {
+	o := newLookupOptions(opts)
+	report := &DeadKeyReport{Namespace: o.namespace, Since: since, Keys: []DeadKey{}}
+	query := `
+		SELECT t.key_path, COUNT(*), COALESCE(MAX(u.count), 0), MAX(u.last_accessed)
+		FROM ui_translations t
+		CROSS JOIN LATERAL (
+			SELECT SUM(count)::bigint AS count, MAX(last_accessed) AS last_accessed FROM ui_key_usage
+			WHERE namespace = t.namespace AND key_path IN (t.key_path, ` + pluralBaseSQL("t.key_path") + `)
+		) u
+		WHERE t.namespace = $1 AND ($3::uuid IS NULL OR t.tenant_id IS NULL OR t.tenant_id = $3::uuid)
+		GROUP BY t.key_path
+		HAVING (MAX(u.last_accessed) IS NULL OR MAX(u.last_accessed) < $2) AND MIN(t.created_at) < $2
+		ORDER BY MAX(u.last_accessed) NULLS FIRST, t.key_path
+	`
+
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, query, o.namespace, since, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var k DeadKey
+			if err = rows.Scan(&k.KeyPath, &k.Rows, &k.Count, &k.LastAccessed); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			report.Keys = append(report.Keys, k)
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// pluralBaseSQL returns the SQL expression for the base key of the key path
+// in column if it is a plural variant, else for the key path itself, as
+// cutPluralSuffix does.
+func pluralBaseSQL(column string) string {
+	return `regexp_replace(` + column + `, '(.)_(` + strings.Join(pluralCategories, "|") + `)$', '\1')`
+}
+
+// WriteText renders the report as an aligned table for review.
+func (r *DeadKeyReport) WriteText(w io.Writer) error {
+	namespace := r.Namespace
+	if namespace == "" {
+		namespace = "(default)"
+	}
+	if _, err := fmt.Fprintf(w, "%s: %d keys not accessed since %s\n", namespace, len(r.Keys), r.Since.Format(time.DateOnly)); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tROWS\tACCESSES\tLAST ACCESSED")
+	for _, k := range r.Keys {
+		last := "never"
+		if k.LastAccessed != nil {
+			last = k.LastAccessed.Format(time.DateOnly)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", k.KeyPath, k.Rows, k.Count, last)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithKeyUsageHandler(t *testing.T) {
+	var got []KeyUsage
+	handler := func(_ context.Context, key KeyUsage) { got = append(got, key) }
+
+	catalog := NewCatalog([]Translation{
+		{Namespace: "inbox", Lang: "en", KeyPath: "title", Value: "Inbox"},
+		{Namespace: "inbox", Lang: "en", KeyPath: "files_one", Value: "{count} file"},
+		{Namespace: "inbox", Lang: "en", KeyPath: "files_other", Value: "{count} files"},
+	})
+	l := NewLocalizer(nil, catalog, nil, WithNamespace("inbox"), WithKeyUsageHandler(handler))
+
+	_, err := l.Lookup(context.Background(), "title", "en")
+	assert.NoError(t, err)
+	_, err = l.Format(context.Background(), "files", "en", map[string]any{"count": 2})
+	assert.NoError(t, err)
+	_, err = l.Lookup(context.Background(), "missing", "en")
+	assert.Error(t, err)
+
+	// Plural variants are reported under their base key
+	assert.Equal(t, []KeyUsage{
+		{Namespace: "inbox", KeyPath: "title", Lang: "en"},
+		{Namespace: "inbox", KeyPath: "files", Lang: "en"},
+	}, got)
+}
+
+func TestKeyUsageRecorder_Aggregates(t *testing.T) {
+	r := NewKeyUsageRecorder(nil)
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	r.add(KeyUsage{KeyPath: "title", Lang: "en"}, first.Add(time.Hour))
+	r.add(KeyUsage{KeyPath: "title", Lang: "de"}, first)
+	r.add(KeyUsage{Namespace: "inbox", KeyPath: "title", Lang: "en"}, first)
+
+	// Languages share the key's row
+	assert.Len(t, r.pending, 2)
+	c := r.pending[keyUsageRow{keyPath: "title"}]
+	if assert.NotNil(t, c) {
+		assert.Equal(t, int64(2), c.count)
+		assert.Equal(t, first.Add(time.Hour), c.lastAccessed)
+	}
+
+	assert.NoError(t, NewKeyUsageRecorder(nil).Flush(context.Background()))
+}
+
+func TestDeadKeyReport_WriteText(t *testing.T) {
+	last := time.Date(2026, 5, 2, 8, 0, 0, 0, time.UTC)
+	report := &DeadKeyReport{
+		Namespace: "checkout",
+		Since:     time.Date(2026, 7, 18, 0, 0, 0, 0, time.UTC),
+		Keys: []DeadKey{
+			{KeyPath: "promo.summer_2024", Rows: 4},
+			{KeyPath: "cart.legacy_note", Rows: 6, Count: 1520, LastAccessed: &last},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `checkout: 2 keys not accessed since 2026-07-18
+KEY                ROWS  ACCESSES  LAST ACCESSED
+promo.summer_2024  4     0         never
+cart.legacy_note   6     1520      2026-05-02
+`, buf.String())
+}
@@ -0,0 +1,249 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Kinds of MessageIssue.
+const (
+	IssueSyntax             = "syntax"              // the value is not a valid ICU message
+	IssueMissingPlaceholder = "missing_placeholder" // the source has an argument the value lacks
+	IssueExtraPlaceholder   = "extra_placeholder"   // the value has an argument the source lacks
+	IssueRenamedPlaceholder = "renamed_placeholder" // an argument of the source appears under another name
+	IssueTagMismatch        = "tag_mismatch"        // the value's HTML tags differ from the source's
+	IssuePluralCategories   = "plural_categories"   // a plural lacks categories its language requires
+)
+
+// MessageIssue is a problem found in one translation.
+type MessageIssue struct {
+	Namespace string  `json:"namespace"`
+	KeyPath   string  `json:"key_path"`
+	Lang      string  `json:"lang"`
+	TenantID  *string `json:"tenant_id,omitempty"`
+	UserID    *string `json:"user_id,omitempty"`
+	Scope     string  `json:"scope,omitempty"`
+	Variant   string  `json:"variant,omitempty"`
+	Kind      string  `json:"kind"`
+	Detail    string  `json:"detail"`
+}
+
+// ValidationReport lists the issues ValidateMessages found.
+type ValidationReport struct {
+	SourceLang string         `json:"source_lang"`
+	Issues     []MessageIssue `json:"issues"`
+}
+
+// htmlTag matches opening, closing and self-closing HTML tags. The tag name
+// must follow "<" or "</" directly, so a literal "<" in text such as
+// "a < b and c > d" is not taken for a tag.
+var htmlTag = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9-]*)(?:\s[^<>]*?)?\s*(/?)>`)
+
+// ValidateMessages compares every translation with the global translation of
+// the same namespace and key in sourceLang. It reports values that are not
+// valid ICU messages, placeholders missing, added or renamed relative to the
+// source, HTML tags that differ from the source's, and plurals lacking
+// categories their language requires (see ValidatePlurals). Translations
+// without a source are only checked on their own.
+//
+// Use it on LoadDir's result to check files before importing them, or use
+// ValidateStoredMessages to check the database.
+func ValidateMessages(translations []Translation, sourceLang string) *ValidationReport {
+	report := &ValidationReport{SourceLang: sourceLang, Issues: []MessageIssue{}}
+
+	type sourceKey struct{ namespace, keyPath string }
+	type source struct {
+		args []string
+		tags []string
+		ok   bool // the source parsed
+	}
+	sources := map[sourceKey]source{}
+	for _, t := range translations {
+		if t.Lang != sourceLang || t.TenantID != nil || t.UserID != nil || t.Scope != "" || t.Variant != ControlVariant {
+			continue
+		}
+		s := source{tags: htmlTags(t.Value)}
+		if m, err := ParseMessage(t.Value); err == nil {
+			s.args, s.ok = m.Arguments(), true
+		}
+		sources[sourceKey{namespace: t.Namespace, keyPath: t.KeyPath}] = s
+	}
+
+	for _, t := range translations {
+		issue := func(kind, detail string) {
+			report.Issues = append(report.Issues, MessageIssue{
+				Namespace: t.Namespace, KeyPath: t.KeyPath, Lang: t.Lang,
+				TenantID: t.TenantID, UserID: t.UserID, Scope: t.Scope, Variant: t.Variant,
+				Kind: kind, Detail: detail,
+			})
+		}
+
+		m, err := ParseMessage(t.Value)
+		if err != nil {
+			issue(IssueSyntax, err.Error())
+		}
+		s, hasSource := sources[sourceKey{namespace: t.Namespace, keyPath: t.KeyPath}]
+		if !hasSource {
+			continue
+		}
+		if err == nil && s.ok {
+			missing, extra := difference(s.args, m.Arguments()), difference(m.Arguments(), s.args)
+			for len(missing) > 0 && len(extra) > 0 {
+				issue(IssueRenamedPlaceholder, fmt.Sprintf("{%s} became {%s}", missing[0], extra[0]))
+				missing, extra = missing[1:], extra[1:]
+			}
+			for _, name := range missing {
+				issue(IssueMissingPlaceholder, "{"+name+"}")
+			}
+			for _, name := range extra {
+				issue(IssueExtraPlaceholder, "{"+name+"}")
+			}
+		}
+		tags := htmlTags(t.Value)
+		missing, extra := difference(s.tags, tags), difference(tags, s.tags)
+		if len(missing) > 0 || len(extra) > 0 {
+			var details []string
+			if len(missing) > 0 {
+				details = append(details, "missing "+strings.Join(missing, " "))
+			}
+			if len(extra) > 0 {
+				details = append(details, "extra "+strings.Join(extra, " "))
+			}
+			issue(IssueTagMismatch, strings.Join(details, ", "))
+		}
+	}
+
+	for _, p := range ValidatePlurals(translations) {
+		detail := "missing " + strings.Join(p.Missing, ", ")
+		if p.Argument != "" {
+			detail = "{" + p.Argument + "} " + detail
+		}
+		report.Issues = append(report.Issues, MessageIssue{
+			Namespace: p.Namespace, KeyPath: p.KeyPath, Lang: p.Lang,
+			Kind: IssuePluralCategories, Detail: detail,
+		})
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.KeyPath != b.KeyPath {
+			return a.KeyPath < b.KeyPath
+		}
+		return a.Lang < b.Lang
+	})
+	return report
+}
+
+// ValidateStoredMessages runs ValidateMessages over the approved values
+// stored in the namespace (the default one without WithNamespace), overrides
+// included. With WithTenant only global rows and that tenant's rows are
+// checked.
+func ValidateStoredMessages(ctx context.Context, conn *pgx.Conn, sourceLang string, opts ...LookupOption) (*ValidationReport, error) {
+	o := newLookupOptions(opts)
+	var translations []Translation
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, `
+			SELECT tenant_id::text, user_id::text, scope, variant, key_path, lang, approved_value FROM ui_translations
+			WHERE namespace = $1 AND ($2::uuid IS NULL OR tenant_id IS NULL OR tenant_id = $2::uuid)
+			AND approved_value IS NOT NULL
+			ORDER BY key_path, lang
+		`, o.namespace, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			t := Translation{Namespace: o.namespace}
+			if err = rows.Scan(&t.TenantID, &t.UserID, &t.Scope, &t.Variant, &t.KeyPath, &t.Lang, &t.Value); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			translations = append(translations, t)
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ValidateMessages(translations, sourceLang), nil
+}
+
+// Err returns an error listing the issues, or nil if there are none, for
+// callers that treat any issue as fatal.
+func (r *ValidationReport) Err() error {
+	var errs []error
+	for _, i := range r.Issues {
+		errs = append(errs, fmt.Errorf("%s %s: %s: %s", i.Lang, i.KeyPath, i.Kind, i.Detail))
+	}
+	return errors.Join(errs...)
+}
+
+// WriteText renders the report as an aligned table for review.
+func (r *ValidationReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "source %s: %d issues\n", r.SourceLang, len(r.Issues)); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tLANG\tKIND\tDETAIL")
+	for _, i := range r.Issues {
+		key := i.KeyPath
+		if i.Namespace != "" {
+			key = i.Namespace + ":" + key
+		}
+		lang := i.Lang
+		switch {
+		case i.UserID != nil:
+			lang += " (user)"
+		case i.Scope != "":
+			lang += " (" + i.Scope + ")"
+		case i.TenantID != nil:
+			lang += " (tenant)"
+		}
+		if i.Variant != ControlVariant {
+			lang += " [" + i.Variant + "]"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", key, lang, i.Kind, i.Detail)
+	}
+	return tw.Flush()
+}
+
+// htmlTags returns the HTML tags of value, normalized to "<b>", "</b>" and
+// "<br/>", in order of appearance.
+func htmlTags(value string) []string {
+	var tags []string
+	for _, m := range htmlTag.FindAllStringSubmatch(value, -1) {
+		tags = append(tags, "<"+m[1]+strings.ToLower(m[2])+m[3]+">")
+	}
+	return tags
+}
+
+// difference returns the elements of a not matched by an element of b,
+// counting repeated elements separately.
+func difference(a, b []string) []string {
+	counts := map[string]int{}
+	for _, s := range b {
+		counts[s]++
+	}
+	var diff []string
+	for _, s := range a {
+		if counts[s] > 0 {
+			counts[s]--
+			continue
+		}
+		diff = append(diff, s)
+	}
+	return diff
+}
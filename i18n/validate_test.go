@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateMessages(t *testing.T) {
+	report := ValidateMessages([]Translation{
+		{Lang: "en", KeyPath: "greeting", Value: "Hello <b>{name}</b>, you have {count, plural, one {# message} other {# messages}}"},
+		{Lang: "es", KeyPath: "greeting", Value: "Hola <b>{nombre}</b>, tienes {count, plural, one {# mensaje} other {# mensajes}}"},
+		{Lang: "de", KeyPath: "greeting", Value: "Hallo <strong>{name}</strong>, du hast {count, plural, one {# Nachricht} other {# Nachrichten}} {extra}"},
+		{Lang: "fr", KeyPath: "greeting", Value: "Bonjour {name}, vous avez {count, plural, one {# message} other {# messages}"},
+		{Lang: "ru", KeyPath: "greeting", Value: "Привет <b>{name}</b>, у вас {count, plural, one {# сообщение} other {# сообщений}}"},
+		{Lang: "it", KeyPath: "greeting", TenantID: stringPtr("org-1"), Value: "Ciao <b>{name}</b>"},
+		{Lang: "en", KeyPath: "farewell", Value: "Bye {name}"},
+		{Lang: "es", KeyPath: "farewell", Value: "Adiós {name}"},
+		{Lang: "es", KeyPath: "orphan", Value: "Sin fuente {x"},
+	}, "en")
+
+	assert.Equal(t, []MessageIssue{
+		{KeyPath: "greeting", Lang: "de", Kind: IssueExtraPlaceholder, Detail: "{extra}"},
+		{KeyPath: "greeting", Lang: "de", Kind: IssueTagMismatch, Detail: "missing <b> </b>, extra <strong> </strong>"},
+		{KeyPath: "greeting", Lang: "es", Kind: IssueRenamedPlaceholder, Detail: "{name} became {nombre}"},
+		{KeyPath: "greeting", Lang: "fr", Kind: IssueSyntax, Detail: "message syntax error at offset 76: expected \"}\", found end of message"},
+		{KeyPath: "greeting", Lang: "fr", Kind: IssueTagMismatch, Detail: "missing <b> </b>"},
+		{KeyPath: "greeting", Lang: "it", TenantID: stringPtr("org-1"), Kind: IssueMissingPlaceholder, Detail: "{count}"},
+		{KeyPath: "greeting", Lang: "ru", Kind: IssuePluralCategories, Detail: "{count} missing few, many"},
+		{KeyPath: "orphan", Lang: "es", Kind: IssueSyntax, Detail: "message syntax error at offset 13: expected ',', found end of message"},
+	}, report.Issues)
+	assert.Error(t, report.Err())
+
+	clean := ValidateMessages([]Translation{
+		{Lang: "en", KeyPath: "farewell", Value: "Bye {name}"},
+		{Lang: "es", KeyPath: "farewell", Value: "Adiós {name}"},
+	}, "en")
+	assert.Empty(t, clean.Issues)
+	assert.NoError(t, clean.Err())
+}
+
+func TestHTMLTags(t *testing.T) {
+	assert.Equal(t, []string{"<b>", "</b>", "<br/>", "<a>", "</a>"}, htmlTags(`<B>bold</b><br /><a href="/x" class="y">link</a>`))
+	// A literal "<" is text, not a tag
+	assert.Empty(t, htmlTags("a < b and c > d"))
+	assert.Equal(t, []string{"<em>", "</em>"}, htmlTags("1 < 2 is <em>true</em>"))
+
+	report := ValidateMessages([]Translation{
+		{Lang: "en", KeyPath: "compare", Value: "Show items < {max} and > {min}"},
+		{Lang: "de", KeyPath: "compare", Value: "Artikel < {max} und > {min} anzeigen"},
+		{Lang: "fr", KeyPath: "compare", Value: "Articles <{max} et > {min}"},
+	}, "en")
+	assert.Empty(t, report.Issues)
+}
+
+func TestValidationReport_WriteText(t *testing.T) {
+	report := &ValidationReport{SourceLang: "en", Issues: []MessageIssue{
+		{Namespace: "checkout", KeyPath: "cart.total", Lang: "es", Kind: IssueMissingPlaceholder, Detail: "{amount}"},
+		{KeyPath: "greeting", Lang: "de", TenantID: stringPtr("org-1"), Kind: IssueTagMismatch, Detail: "missing <b> </b>"},
+	}}
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `source en: 2 issues
+KEY                  LANG         KIND                 DETAIL
+checkout:cart.total  es           missing_placeholder  {amount}
+greeting             de (tenant)  tag_mismatch         missing <b> </b>
+`, buf.String())
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"source_lang": "en", "issues": [
+		{"namespace": "checkout", "key_path": "cart.total", "lang": "es", "kind": "missing_placeholder", "detail": "{amount}"},
+		{"namespace": "", "key_path": "greeting", "lang": "de", "tenant_id": "org-1", "kind": "tag_mismatch", "detail": "missing <b> </b>"}
+	]}`, string(data))
+}
@@ -0,0 +1,196 @@
+package i18n
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"text/tabwriter"
+)
+
+// ControlVariant is the variant of ordinary translations, served to subjects
+// outside an experiment and whenever the assigned variant has no value.
+const ControlVariant = ""
+
+// Variant is one arm of an Experiment. Subjects are assigned to it in
+// proportion to its weight among the experiment's variants.
+type Variant struct {
+	Name   string `json:"name"` // ControlVariant for the control arm
+	Weight int    `json:"weight"`
+}
+
+// Experiment assigns subjects to wordings stored with Translation.Variant.
+// The assignment depends only on the experiment's ID and the subject, so a
+// subject keeps its variant across lookups, processes and restarts, and
+// changing the ID reshuffles everyone.
+type Experiment struct {
+	ID       string    `json:"id"`
+	Variants []Variant `json:"variants"`
+}
+
+// Assign returns the variant subjectID is assigned to. Variants without a
+// positive weight get no subject; without any the control is returned.
+func (e Experiment) Assign(subjectID string) string {
+	total := 0
+	for _, v := range e.Variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	if total == 0 {
+		return ControlVariant
+	}
+
+	sum := sha256.Sum256([]byte(e.ID + "\x00" + subjectID))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for _, v := range e.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+		if bucket < v.Weight {
+			return v.Name
+		}
+		bucket -= v.Weight
+	}
+	return ControlVariant
+}
+
+// WithVariant makes a lookup or export serve the values stored for variant,
+// falling back to the control value for keys the variant does not have.
+// Within each level of the scope chain the variant wins over the control, so
+// a tenant's override of a key still wins over a global variant of it.
+func WithVariant(variant string) LookupOption {
+	return func(o *lookupOptions) {
+		o.variant = variant
+	}
+}
+
+// variantSQL returns a condition matching the rows a lookup with o reads:
+// the control rows and those of o's variant.
+func (o lookupOptions) variantSQL(args *queryArgs) string {
+	if o.variant == ControlVariant {
+		return "variant = ''"
+	}
+	return "variant IN ('', " + args.add(o.variant) + ")"
+}
+
+// controlLast orders the variant row of a key before its control row.
+const controlLast = "(variant = '')"
+
+// GetVariantTranslation assigns subjectID to a variant of exp and looks up
+// keyPath as GetTranslation would with WithVariant. It returns the value and
+// the variant it was stored for, ControlVariant if the assigned variant had
+// none, so exposures can be logged accurately.
+func GetVariantTranslation(ctx context.Context, conn *pgx.Conn, exp Experiment, subjectID string, userID *string, keyPath, lang string, opts ...LookupOption) (string, string, error) {
+	o := newLookupOptions(append(opts, WithVariant(exp.Assign(subjectID))))
+	return lookup(ctx, conn, userID, keyPath, lang, o)
+}
+
+// VariantAssignment is the variant a subject is assigned to.
+type VariantAssignment struct {
+	SubjectID string `json:"subject_id"`
+	Variant   string `json:"variant"`
+}
+
+// VariantSummary describes one variant of a VariantReport.
+type VariantSummary struct {
+	Variant
+	Subjects int      `json:"subjects"` // subjects assigned to it
+	Keys     []string `json:"keys"`     // keys stored for it; the others serve the control value
+}
+
+// VariantReport lists which subjects see which variant of an experiment.
+type VariantReport struct {
+	Experiment  string              `json:"experiment"`
+	Lang        string              `json:"lang"`
+	Namespace   string              `json:"namespace"`
+	Variants    []VariantSummary    `json:"variants"`
+	Assignments []VariantAssignment `json:"assignments"`
+}
+
+// ExportVariantAssignments assigns each of subjectIDs to a variant of exp
+// and reports, per variant, the subjects assigned and the keys of lang it
+// serves in the namespace (the default one without WithNamespace): those
+// with an approved value in effect now. With WithTenant the tenant's variant
+// rows count as well. The report marshals to JSON as is and renders as a
+// table with WriteText.
+func ExportVariantAssignments(ctx context.Context, conn *pgx.Conn, exp Experiment, subjectIDs []string, lang string, opts ...LookupOption) (*VariantReport, error) {
+	o := newLookupOptions(opts)
+	report := &VariantReport{Experiment: exp.ID, Lang: lang, Namespace: o.namespace, Assignments: make([]VariantAssignment, 0, len(subjectIDs))}
+
+	index := map[string]int{}
+	names := make([]string, 0, len(exp.Variants))
+	for _, v := range exp.Variants {
+		index[v.Name] = len(report.Variants)
+		names = append(names, v.Name)
+		report.Variants = append(report.Variants, VariantSummary{Variant: v, Keys: []string{}})
+	}
+	for _, subjectID := range subjectIDs {
+		a := VariantAssignment{SubjectID: subjectID, Variant: exp.Assign(subjectID)}
+		if i, ok := index[a.Variant]; ok {
+			report.Variants[i].Subjects++
+		}
+		report.Assignments = append(report.Assignments, a)
+	}
+
+	err := inTenant(ctx, conn, o.tenantID, func(q querier) error {
+		rows, err := q.Query(ctx, `
+			SELECT DISTINCT variant, key_path FROM ui_translations
+			WHERE lang = $1 AND namespace = $2 AND variant = ANY($3)
+			AND user_id IS NULL AND scope = '' AND (tenant_id IS NULL OR tenant_id = $4::uuid)
+			AND approved_value IS NOT NULL AND `+activeSQL("", "NOW()")+`
+			ORDER BY variant, key_path
+		`, lang, o.namespace, names, o.tenantID)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var variant, keyPath string
+			if err = rows.Scan(&variant, &keyPath); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			if i, ok := index[variant]; ok {
+				report.Variants[i].Keys = append(report.Variants[i].Keys, keyPath)
+			}
+		}
+		if rows.Err() != nil {
+			return fmt.Errorf("row iteration error: %w", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// WriteText renders the report as an aligned table of variants followed by
+// one line per subject.
+func (r *VariantReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "experiment %s, %s\n", r.Experiment, r.Lang); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIANT\tWEIGHT\tSUBJECTS\tKEYS")
+	for _, v := range r.Variants {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", variantLabel(v.Name), v.Weight, v.Subjects, len(v.Keys))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "SUBJECT\tVARIANT")
+	for _, a := range r.Assignments {
+		fmt.Fprintf(tw, "%s\t%s\n", a.SubjectID, variantLabel(a.Variant))
+	}
+	return tw.Flush()
+}
+
+// variantLabel shows the control variant as "(control)".
+func variantLabel(variant string) string {
+	if variant == ControlVariant {
+		return "(control)"
+	}
+	return variant
+}
@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExperiment_Assign(t *testing.T) {
+	exp := Experiment{ID: "cta", Variants: []Variant{{Name: ControlVariant, Weight: 1}, {Name: "urgent", Weight: 3}, {Name: "off", Weight: 0}}}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		subject := fmt.Sprintf("user-%d", i)
+		variant := exp.Assign(subject)
+		assert.Equal(t, variant, exp.Assign(subject), "assignment must be deterministic")
+		counts[variant]++
+	}
+	assert.Zero(t, counts["off"])
+	assert.InDelta(t, 1000, counts[ControlVariant], 150)
+	assert.InDelta(t, 3000, counts["urgent"], 150)
+
+	// Another experiment ID reshuffles subjects
+	other := Experiment{ID: "cta-2", Variants: exp.Variants}
+	moved := 0
+	for i := 0; i < 100; i++ {
+		subject := fmt.Sprintf("user-%d", i)
+		if exp.Assign(subject) != other.Assign(subject) {
+			moved++
+		}
+	}
+	assert.Greater(t, moved, 0)
+
+	assert.Equal(t, ControlVariant, Experiment{ID: "empty"}.Assign("user-1"))
+	assert.Equal(t, ControlVariant, Experiment{ID: "off", Variants: []Variant{{Name: "b", Weight: 0}}}.Assign("user-1"))
+}
+
+func TestVariantSQL(t *testing.T) {
+	var args queryArgs
+	assert.Equal(t, "variant = ''", newLookupOptions(nil).variantSQL(&args))
+	assert.Empty(t, args)
+	assert.Equal(t, "variant IN ('', $1)", newLookupOptions([]LookupOption{WithVariant("urgent")}).variantSQL(&args))
+	assert.Equal(t, queryArgs{"urgent"}, args)
+}
+
+func TestVariantReport_WriteText(t *testing.T) {
+	report := &VariantReport{
+		Experiment: "cta",
+		Lang:       "en",
+		Variants: []VariantSummary{
+			{Variant: Variant{Name: ControlVariant, Weight: 1}, Subjects: 1, Keys: []string{"cta.buy", "cta.cancel"}},
+			{Variant: Variant{Name: "urgent", Weight: 1}, Subjects: 1, Keys: []string{"cta.buy"}},
+		},
+		Assignments: []VariantAssignment{{SubjectID: "u1", Variant: "urgent"}, {SubjectID: "u2", Variant: ControlVariant}},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `experiment cta, en
+VARIANT    WEIGHT  SUBJECTS  KEYS
+(control)  1       1         2
+urgent     1       1         1
+
+SUBJECT  VARIANT
+u1       urgent
+u2       (control)
+`, buf.String())
+}
@@ -0,0 +1,24 @@
+-- Adds the namespace column. Existing rows move to the default namespace ''
+-- and the unique key gains the namespace.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS namespace TEXT NOT NULL DEFAULT '';
+
+-- Replace the unique key, whatever name it was given
+DO $$
+DECLARE
+    c TEXT;
+BEGIN
+    FOR c IN
+        SELECT conname FROM pg_constraint
+        WHERE conrelid = 'ui_translations'::regclass AND contype = 'u'
+    LOOP
+        EXECUTE format('ALTER TABLE ui_translations DROP CONSTRAINT %I', c);
+    END LOOP;
+END $$;
+
+ALTER TABLE ui_translations
+    ADD UNIQUE (user_id, namespace, key_path, lang);
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Adds the tenant_id column for organization-level overrides. Existing rows
+-- stay global (NULL tenant) and the unique key gains the tenant.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS tenant_id UUID;
+
+-- Replace the unique key, whatever name it was given
+DO $$
+DECLARE
+    c TEXT;
+BEGIN
+    FOR c IN
+        SELECT conname FROM pg_constraint
+        WHERE conrelid = 'ui_translations'::regclass AND contype = 'u'
+    LOOP
+        EXECUTE format('ALTER TABLE ui_translations DROP CONSTRAINT %I', c);
+    END LOOP;
+END $$;
+
+ALTER TABLE ui_translations
+    ADD UNIQUE (tenant_id, user_id, namespace, key_path, lang);
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Adds the scope column for overrides within a tenant. Existing rows get no
+-- scope ('') and the unique key gains the scope.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS scope TEXT NOT NULL DEFAULT '';
+
+-- Replace the unique key, whatever name it was given
+DO $$
+DECLARE
+    c TEXT;
+BEGIN
+    FOR c IN
+        SELECT conname FROM pg_constraint
+        WHERE conrelid = 'ui_translations'::regclass AND contype = 'u'
+    LOOP
+        EXECUTE format('ALTER TABLE ui_translations DROP CONSTRAINT %I', c);
+    END LOOP;
+END $$;
+
+ALTER TABLE ui_translations
+    ADD UNIQUE (tenant_id, user_id, scope, namespace, key_path, lang);
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Adds the audit trail written by CopyOverrides, PromoteToGlobal and the
+-- review workflow.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS ui_translation_audit
+(
+    id               BIGSERIAL PRIMARY KEY,
+    action           TEXT NOT NULL,
+    source_tenant_id UUID,
+    source_user_id   UUID,
+    source_scope     TEXT,
+    tenant_id        UUID,
+    user_id          UUID,
+    scope            TEXT NOT NULL DEFAULT '',
+    namespace        TEXT NOT NULL DEFAULT '',
+    key_path         TEXT NOT NULL,
+    lang             TEXT NOT NULL,
+    old_value        TEXT,
+    new_value        TEXT,
+    actor            UUID,
+    created_at       TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMIT;
@@ -0,0 +1,38 @@
+-- Makes the unique key of ui_translations NULL-safe. Before this migration
+-- rows with a NULL tenant_id or user_id never conflicted, so every upsert of a
+-- global translation inserted another copy. Requires PostgreSQL 15+.
+BEGIN;
+
+-- Keep the most recently updated copy of each duplicated row
+DELETE FROM ui_translations
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT id,
+               ROW_NUMBER() OVER (
+                   PARTITION BY tenant_id, user_id, scope, namespace, key_path, lang
+                   ORDER BY updated_at DESC NULLS LAST, id DESC
+               ) AS copy
+        FROM ui_translations
+    ) ranked
+    WHERE copy > 1
+);
+
+-- Replace the old NULLS DISTINCT constraint, whatever name it was given
+DO $$
+DECLARE
+    c TEXT;
+BEGIN
+    FOR c IN
+        SELECT conname FROM pg_constraint
+        WHERE conrelid = 'ui_translations'::regclass AND contype = 'u'
+    LOOP
+        EXECUTE format('ALTER TABLE ui_translations DROP CONSTRAINT %I', c);
+    END LOOP;
+END $$;
+
+ALTER TABLE ui_translations
+    ADD CONSTRAINT ui_translations_key_unique
+        UNIQUE NULLS NOT DISTINCT (tenant_id, user_id, scope, namespace, key_path, lang);
+
+COMMIT;
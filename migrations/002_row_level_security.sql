@@ -0,0 +1,43 @@
+-- Optional: enforces tenant isolation in the database. Once applied, a
+-- session only sees global rows plus the rows of the tenant named in the
+-- app.tenant_id setting, and can only write rows of that tenant (or global
+-- rows when the setting is empty). The library sets app.tenant_id per
+-- transaction for lookups made WithTenant and for batches of a single tenant.
+--
+-- FORCE applies the policies to the table owner as well. Platform-wide jobs
+-- that span tenants (CopyOverrides between tenants, PromoteToGlobal, mixed
+-- imports) must connect with a role that has BYPASSRLS.
+BEGIN;
+
+ALTER TABLE ui_translations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ui_translations FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY ui_translations_tenant_isolation ON ui_translations
+    USING (tenant_id IS NULL
+        OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (tenant_id IS NOT DISTINCT FROM NULLIF(current_setting('app.tenant_id', true), '')::uuid);
+
+ALTER TABLE ui_translation_audit ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ui_translation_audit FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY ui_translation_audit_tenant_isolation ON ui_translation_audit
+    USING (tenant_id IS NULL
+        OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (tenant_id IS NOT DISTINCT FROM NULLIF(current_setting('app.tenant_id', true), '')::uuid);
+
+-- ui_missing_keys, if 009_missing_keys.sql already created it; otherwise
+-- that migration adds the policy itself.
+DO $$
+BEGIN
+    IF to_regclass('ui_missing_keys') IS NOT NULL THEN
+        ALTER TABLE ui_missing_keys ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE ui_missing_keys FORCE ROW LEVEL SECURITY;
+
+        CREATE POLICY ui_missing_keys_tenant_isolation ON ui_missing_keys
+            USING (tenant_id IS NULL
+                OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+            WITH CHECK (tenant_id IS NOT DISTINCT FROM NULLIF(current_setting('app.tenant_id', true), '')::uuid);
+    END IF;
+END $$;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Reverts 002_row_level_security.sql.
+BEGIN;
+
+DROP POLICY IF EXISTS ui_translations_tenant_isolation ON ui_translations;
+ALTER TABLE ui_translations NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE ui_translations DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS ui_translation_audit_tenant_isolation ON ui_translation_audit;
+ALTER TABLE ui_translation_audit NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE ui_translation_audit DISABLE ROW LEVEL SECURITY;
+
+DO $$
+BEGIN
+    IF to_regclass('ui_missing_keys') IS NOT NULL THEN
+        DROP POLICY IF EXISTS ui_missing_keys_tenant_isolation ON ui_missing_keys;
+        ALTER TABLE ui_missing_keys NO FORCE ROW LEVEL SECURITY;
+        ALTER TABLE ui_missing_keys DISABLE ROW LEVEL SECURITY;
+    END IF;
+END $$;
+
+COMMIT;
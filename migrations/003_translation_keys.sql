@@ -0,0 +1,17 @@
+-- Adds per-key metadata for translators.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS translation_keys
+(
+    namespace   TEXT NOT NULL DEFAULT '',
+    key_path    TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    notes       TEXT NOT NULL DEFAULT '',
+    screenshot  TEXT NOT NULL DEFAULT '',
+    max_length  INTEGER CHECK (max_length > 0),
+    tags        TEXT[] NOT NULL DEFAULT '{}',
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (namespace, key_path)
+);
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Replaces the tooltip column with a JSONB attributes column holding any
+-- number of per-string variants. Existing tooltips become the "tooltip"
+-- attribute.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+UPDATE ui_translations
+SET attributes = attributes || jsonb_build_object('tooltip', tooltip)
+WHERE tooltip IS NOT NULL AND tooltip <> '';
+
+ALTER TABLE ui_translations
+    DROP COLUMN tooltip;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Records the hash of the source-language value each translation was made
+-- against, so translations can be flagged when their source changes.
+-- Existing rows start without a hash; stamp them once with StampSourceHashes.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS source_hash TEXT;
+
+COMMIT;
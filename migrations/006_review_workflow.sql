@@ -0,0 +1,20 @@
+-- Adds review states: value becomes the latest value, possibly awaiting
+-- review, and approved_value the one lookups serve; pending_attributes holds
+-- the attributes of a pending value until it is approved. Existing rows are
+-- approved as they are.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS approved_value TEXT,
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'approved'
+        CHECK (status IN ('draft', 'in_review', 'approved', 'rejected')),
+    ADD COLUMN IF NOT EXISTS submitted_by UUID,
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS reviewed_by UUID,
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS review_comment TEXT,
+    ADD COLUMN IF NOT EXISTS pending_attributes JSONB;
+
+UPDATE ui_translations SET approved_value = value WHERE approved_value IS NULL AND status = 'approved';
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Adds an optional effective window to translations. effective_from joins
+-- the unique key, so a value scheduled for a launch is stored beside the
+-- current one; lookups serve the value that came into effect last. The end
+-- of a value awaiting review is held in pending_effective_until until it is
+-- approved.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS effective_from TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS effective_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS pending_effective_until TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT ui_translations_effective_window
+        CHECK (effective_until IS NULL OR effective_from IS NULL OR effective_until > effective_from),
+    ADD CONSTRAINT ui_translations_pending_effective_window
+        CHECK (pending_effective_until IS NULL OR effective_from IS NULL OR pending_effective_until > effective_from);
+
+ALTER TABLE ui_translations
+    DROP CONSTRAINT ui_translations_key_unique;
+
+ALTER TABLE ui_translations
+    ADD CONSTRAINT ui_translations_key_unique
+        UNIQUE NULLS NOT DISTINCT (tenant_id, user_id, scope, namespace, key_path, lang, effective_from);
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Adds experiment variants. variant joins the unique key, so each variant of
+-- a key is stored beside the ordinary value, whose variant is ''.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE ui_translations
+    DROP CONSTRAINT ui_translations_key_unique;
+
+ALTER TABLE ui_translations
+    ADD CONSTRAINT ui_translations_key_unique
+        UNIQUE NULLS NOT DISTINCT (tenant_id, user_id, scope, namespace, key_path, lang, variant, effective_from);
+
+COMMIT;
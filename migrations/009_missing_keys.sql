@@ -0,0 +1,34 @@
+-- Stores the keys lookups asked for but found no value for, as recorded by
+-- MissingKeyRecorder: one row per tenant, scope, namespace, key and language.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS ui_missing_keys
+(
+    tenant_id  UUID,
+    scope      TEXT NOT NULL DEFAULT '',
+    namespace  TEXT NOT NULL DEFAULT '',
+    key_path   TEXT NOT NULL,
+    lang       TEXT NOT NULL,
+    count      BIGINT NOT NULL DEFAULT 0,
+    first_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_seen  TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT ui_missing_keys_unique
+        UNIQUE NULLS NOT DISTINCT (tenant_id, scope, namespace, key_path, lang)
+);
+
+-- Where 002_row_level_security.sql is applied, missing keys are isolated per
+-- tenant like translations.
+DO $$
+BEGIN
+    IF (SELECT relrowsecurity FROM pg_class WHERE oid = 'ui_translations'::regclass) THEN
+        ALTER TABLE ui_missing_keys ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE ui_missing_keys FORCE ROW LEVEL SECURITY;
+
+        CREATE POLICY ui_missing_keys_tenant_isolation ON ui_missing_keys
+            USING (tenant_id IS NULL
+                OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+            WITH CHECK (tenant_id IS NOT DISTINCT FROM NULLIF(current_setting('app.tenant_id', true), '')::uuid);
+    END IF;
+END $$;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Stores how often and how recently each key was served, as recorded by
+-- KeyUsageRecorder, so keys no longer used can be found with DeadKeys, and
+-- when each translation was created, so DeadKeys can leave out new keys.
+-- Existing rows take their last update as creation time.
+BEGIN;
+
+ALTER TABLE ui_translations
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE ui_translations SET created_at = COALESCE(updated_at, NOW()) WHERE created_at IS NULL;
+
+ALTER TABLE ui_translations
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ui_key_usage
+(
+    namespace     TEXT NOT NULL DEFAULT '',
+    key_path      TEXT NOT NULL,
+    count         BIGINT NOT NULL DEFAULT 0,
+    last_accessed TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (namespace, key_path)
+);
+
+COMMIT;